@@ -0,0 +1,41 @@
+package mcproto
+
+// AppendVarInt appends the VarInt encoding of v to dst and returns the
+// extended slice, the same append-style encoding/binary has moved
+// towards with AppendVarint. Unlike PutVarInt, it does not return an
+// error: there is nothing for it to fail on short of running out of
+// memory.
+func AppendVarInt(dst []byte, v int32) []byte {
+	return appendVarN(dst, uint64(uint32(v)))
+}
+
+// AppendVarLong appends the VarLong encoding of v to dst.
+func AppendVarLong(dst []byte, v int64) []byte {
+	return appendVarN(dst, uint64(v))
+}
+
+func appendVarN(dst []byte, v uint64) []byte {
+	for v&^cmask != 0 {
+		dst = append(dst, byte(v|msb))
+		v >>= cbits
+	}
+	return append(dst, byte(v))
+}
+
+// AppendString appends s to dst as a VarInt length prefix followed by
+// its bytes, the same layout PutString writes. It does not enforce
+// GetString/PutString's length cap; callers that need that guarantee
+// should check len(s) themselves before calling it.
+func AppendString(dst []byte, s string) []byte {
+	dst = AppendVarInt(dst, int32(len(s)))
+	return append(dst, s...)
+}
+
+// AppendPacket appends p to dst in the same length-prefixed framing
+// PutPacket writes: an outer VarInt length, p.ID as a VarInt, then
+// p.Data.
+func AppendPacket(dst []byte, p Packet) []byte {
+	dst = AppendVarInt(dst, int32(LenVarInt(p.ID)+len(p.Data)))
+	dst = AppendVarInt(dst, p.ID)
+	return append(dst, p.Data...)
+}
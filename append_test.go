@@ -0,0 +1,65 @@
+package mcproto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendVarInt(t *testing.T) {
+	for _, c := range varIntCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		got := AppendVarInt(nil, c.value)
+		if bytes.Compare(got, c.bytes) != 0 {
+			t.Errorf("AppendVarInt(%#v): have %#v, got %#v", c.value, c.bytes, got)
+		}
+	}
+}
+
+func TestAppendVarLong(t *testing.T) {
+	for _, c := range varLongCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		got := AppendVarLong(nil, c.value)
+		if bytes.Compare(got, c.bytes) != 0 {
+			t.Errorf("AppendVarLong(%#v): have %#v, got %#v", c.value, c.bytes, got)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	for _, c := range stringCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		got := AppendString(nil, c.value)
+		if bytes.Compare(got, c.bytes) != 0 {
+			t.Errorf("AppendString(%#v): have %#v, got %#v", c.value, c.bytes, got)
+		}
+	}
+}
+
+func TestAppendPacket(t *testing.T) {
+	for _, c := range packetCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		got := AppendPacket(nil, c.value)
+		if bytes.Compare(got, c.bytes) != 0 {
+			t.Errorf("AppendPacket(%#v): have %#v, got %#v", c.value, c.bytes, got)
+		}
+	}
+}
+
+func TestAppendVarIntPreservesPrefix(t *testing.T) {
+	prefix := []byte{0xaa, 0xbb}
+	got := AppendVarInt(append([]byte{}, prefix...), 300)
+	if bytes.Compare(got[:len(prefix)], prefix) != 0 {
+		t.Errorf("AppendVarInt overwrote dst's existing contents: %#v", got)
+	}
+	if bytes.Compare(got[len(prefix):], []byte{0xac, 0x02}) != 0 {
+		t.Errorf("have %#v, got %#v", []byte{0xac, 0x02}, got[len(prefix):])
+	}
+}
@@ -0,0 +1,128 @@
+// Package cipher implements the AES-128/CFB8 stream cipher Minecraft uses
+// to encrypt a connection once the login encryption handshake completes.
+// Every byte sent after encryption is enabled is encrypted with the
+// shared secret negotiated during login, used as both the AES key and
+// the initial feedback register.
+package cipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const blockSize = aes.BlockSize
+
+// cfb8 implements CFB8 mode over a block cipher by hand: the stdlib's
+// cipher.NewCFBEncrypter/NewCFBDecrypter only implement CFB with a
+// feedback segment the size of the block (CFB128 for AES), which is
+// incompatible with the 8-bit segment Minecraft requires. For each byte,
+// the 16-byte feedback register is run through the block cipher, the
+// first byte of the result masks the data byte, and the register is
+// shifted left by one byte with the ciphertext byte appended.
+type cfb8 struct {
+	block    cipher.Block
+	feedback [blockSize]byte
+	decrypt  bool
+}
+
+func newCFB8(sharedSecret []byte, decrypt bool) (*cfb8, error) {
+	if len(sharedSecret) != blockSize {
+		return nil, errors.New("shared secret must be 16 bytes")
+	}
+
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	c := &cfb8{block: block, decrypt: decrypt}
+	copy(c.feedback[:], sharedSecret)
+	return c, nil
+}
+
+// xorByte encrypts or decrypts a single byte and advances the feedback
+// register, so it must be called with bytes in stream order exactly
+// once each.
+func (c *cfb8) xorByte(b byte) byte {
+	var mask [blockSize]byte
+	c.block.Encrypt(mask[:], c.feedback[:])
+
+	var cipherByte byte
+	if c.decrypt {
+		cipherByte = b
+	} else {
+		cipherByte = b ^ mask[0]
+	}
+
+	copy(c.feedback[:blockSize-1], c.feedback[1:])
+	c.feedback[blockSize-1] = cipherByte
+
+	if c.decrypt {
+		return b ^ mask[0]
+	}
+	return cipherByte
+}
+
+type encryptedReader struct {
+	r io.Reader
+	c *cfb8
+}
+
+// NewEncryptedReader returns an io.Reader that decrypts bytes read from r
+// using AES-128/CFB8 with sharedSecret as both the key and the initial
+// feedback register. sharedSecret must be 16 bytes.
+func NewEncryptedReader(r io.Reader, sharedSecret []byte) (io.Reader, error) {
+	c, err := newCFB8(sharedSecret, true)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedReader{r: r, c: c}, nil
+}
+
+func (er *encryptedReader) Read(p []byte) (int, error) {
+	n, err := er.r.Read(p)
+	for i := 0; i < n; i++ {
+		p[i] = er.c.xorByte(p[i])
+	}
+	return n, err
+}
+
+type encryptedWriter struct {
+	w   io.Writer
+	c   *cfb8
+	buf []byte
+}
+
+// NewEncryptedWriter returns an io.Writer that encrypts bytes before
+// writing them to w using AES-128/CFB8 with sharedSecret as both the key
+// and the initial feedback register. sharedSecret must be 16 bytes.
+func NewEncryptedWriter(w io.Writer, sharedSecret []byte) (io.Writer, error) {
+	c, err := newCFB8(sharedSecret, false)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedWriter{w: w, c: c}, nil
+}
+
+func (ew *encryptedWriter) Write(p []byte) (int, error) {
+	if cap(ew.buf) < len(p) {
+		ew.buf = make([]byte, len(p))
+	}
+	buf := ew.buf[:len(p)]
+	for i, b := range p {
+		buf[i] = ew.c.xorByte(b)
+	}
+
+	// The feedback register has already advanced past every byte in buf,
+	// so a short write here would desynchronize the cipher with the peer;
+	// callers are expected to write to a connection that either writes
+	// buf in full or returns an error.
+	n, err := ew.w.Write(buf)
+	if n > len(p) {
+		n = len(p)
+	}
+	return n, err
+}
@@ -0,0 +1,195 @@
+package mcproto
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxDecompressedPacketSize bounds how large a single packet may grow to
+// once decompressed, guarding against zlib-bomb style denial of service.
+const maxDecompressedPacketSize = 1 << 21 // 2 MiB
+
+var zlibReaderPool = sync.Pool{}
+
+// getZlibReader returns a zlib reader over r, reusing a pooled reader via
+// zlib.Resetter when one is available.
+func getZlibReader(r io.Reader) (io.ReadCloser, error) {
+	if zr, ok := zlibReaderPool.Get().(zlib.Resetter); ok {
+		if err := zr.Reset(r, nil); err != nil {
+			return nil, err
+		}
+		return zr.(io.ReadCloser), nil
+	}
+	return zlib.NewReader(r)
+}
+
+func putZlibReader(zr io.ReadCloser) {
+	zlibReaderPool.Put(zr)
+}
+
+var zlibWriterPool = sync.Pool{
+	New: func() interface{} { return zlib.NewWriter(io.Discard) },
+}
+
+func getZlibWriter(w io.Writer) *zlib.Writer {
+	zw := zlibWriterPool.Get().(*zlib.Writer)
+	zw.Reset(w)
+	return zw
+}
+
+func putZlibWriter(zw *zlib.Writer) {
+	zlibWriterPool.Put(zw)
+}
+
+// CompressedPacketReader reads packets framed with the post-threshold
+// compression layer Minecraft switches to after login: an outer length,
+// an uncompressed data length (0 meaning the packet was not compressed),
+// and either the raw or zlib-compressed packet id and data. It is not
+// safe for concurrent use.
+type CompressedPacketReader struct {
+	pr        *PacketReader
+	threshold int
+	buf       []byte
+}
+
+// NewCompressedPacketReader returns a CompressedPacketReader that reads
+// from r. threshold is the compression threshold the peer was configured
+// with; -1 disables compression entirely.
+func NewCompressedPacketReader(r io.Reader, threshold int) *CompressedPacketReader {
+	return &CompressedPacketReader{pr: NewPacketReader(r), threshold: threshold}
+}
+
+// SetCompressionThreshold switches the reader's compression mode, for use
+// when a Set Compression packet is received mid-stream.
+func (cr *CompressedPacketReader) SetCompressionThreshold(threshold int) {
+	cr.threshold = threshold
+}
+
+// leftover returns any bytes already read off the underlying io.Reader
+// into cr's internal bufio.Reader but not yet consumed as packet data.
+func (cr *CompressedPacketReader) leftover() []byte {
+	return cr.pr.leftover()
+}
+
+// ReadPacket reads and returns the next packet from the stream.
+func (cr *CompressedPacketReader) ReadPacket() (Packet, error) {
+	if cr.threshold < 0 {
+		return cr.pr.ReadPacket()
+	}
+
+	// Reuse the plain packet framing to pull off the outer length prefix:
+	// what ReadPacketBuf treats as the packet id is the dataLength VarInt,
+	// and what it treats as packet data is the (possibly compressed) blob.
+	frame, buf, err := cr.pr.ReadPacketBuf(cr.buf)
+	if err != nil {
+		return Packet{}, err
+	}
+	cr.buf = buf
+
+	dataLength := frame.ID
+	payload := frame.Data
+
+	var raw []byte
+	if dataLength == 0 {
+		raw = payload
+	} else {
+		if dataLength < 0 || dataLength > maxDecompressedPacketSize {
+			return Packet{}, errors.WithStack(errValueTooLarge)
+		}
+
+		zr, err := getZlibReader(bytes.NewReader(payload))
+		if err != nil {
+			return Packet{}, err
+		}
+		defer putZlibReader(zr)
+
+		raw = make([]byte, dataLength)
+		if _, err := io.ReadFull(zr, raw); err != nil {
+			return Packet{}, err
+		}
+		if _, err := zr.Read(make([]byte, 1)); err != io.EOF {
+			return Packet{}, errors.New("compressed packet data length does not match declared length")
+		}
+	}
+
+	id, m, err := GetVarInt(raw)
+	if err != nil {
+		return Packet{}, err
+	}
+
+	data := make([]byte, len(raw)-m)
+	copy(data, raw[m:])
+	return Packet{ID: id, Data: data}, nil
+}
+
+// CompressedPacketWriter writes packets using the post-threshold
+// compression framing. It is not safe for concurrent use.
+type CompressedPacketWriter struct {
+	pw        *PacketWriter
+	threshold int
+	raw       bytes.Buffer
+	compr     bytes.Buffer
+}
+
+// NewCompressedPacketWriter returns a CompressedPacketWriter that writes
+// to w. threshold is the minimum uncompressed size, in bytes, of the
+// packet id and data at which a packet is compressed; -1 disables
+// compression entirely.
+func NewCompressedPacketWriter(w io.Writer, threshold int) *CompressedPacketWriter {
+	return &CompressedPacketWriter{pw: NewPacketWriter(w), threshold: threshold}
+}
+
+// SetCompressionThreshold switches the writer's compression mode, for use
+// when sending a Set Compression packet.
+func (cw *CompressedPacketWriter) SetCompressionThreshold(threshold int) {
+	cw.threshold = threshold
+}
+
+// WritePacket writes p to the stream, compressing it first if the writer
+// is past its compression threshold.
+func (cw *CompressedPacketWriter) WritePacket(p Packet) error {
+	if cw.threshold < 0 {
+		return cw.pw.WritePacket(p)
+	}
+
+	cw.raw.Reset()
+	idBuf := make([]byte, maxIntBytes)
+	n, err := PutVarInt(idBuf, p.ID)
+	if err != nil {
+		return err
+	}
+	cw.raw.Write(idBuf[:n])
+	cw.raw.Write(p.Data)
+
+	var frame []byte
+	if cw.raw.Len() < cw.threshold {
+		frame = append(frame, 0x00) // dataLength VarInt(0) marks this packet as uncompressed
+		frame = append(frame, cw.raw.Bytes()...)
+	} else {
+		cw.compr.Reset()
+		zw := getZlibWriter(&cw.compr)
+		if _, err := zw.Write(cw.raw.Bytes()); err != nil {
+			putZlibWriter(zw)
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			putZlibWriter(zw)
+			return err
+		}
+		putZlibWriter(zw)
+
+		dataLengthBuf := make([]byte, maxIntBytes)
+		m, err := PutVarInt(dataLengthBuf, int32(cw.raw.Len()))
+		if err != nil {
+			return err
+		}
+		frame = append(frame, dataLengthBuf[:m]...)
+		frame = append(frame, cw.compr.Bytes()...)
+	}
+
+	return cw.pw.writeFramed(frame)
+}
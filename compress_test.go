@@ -0,0 +1,77 @@
+package mcproto
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCompressedPacketReaderWriter(t *testing.T) {
+	cases := []struct {
+		threshold int
+		packet    Packet
+	}{
+		{-1, Packet{ID: 0x00, Data: []byte{0x01, 0x02, 0x03}}},
+		{256, Packet{ID: 0x00, Data: []byte{0x01, 0x02, 0x03}}}, // below threshold, sent uncompressed
+		{2, Packet{ID: 0x00, Data: bytes.Repeat([]byte{0xab}, 512)}}, // above threshold, compressed
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		w := NewCompressedPacketWriter(&buf, c.threshold)
+		if err := w.WritePacket(c.packet); err != nil {
+			t.Errorf("WritePacket(%#v) with threshold %d failed: %#v", c.packet, c.threshold, err)
+			continue
+		}
+
+		r := NewCompressedPacketReader(&buf, c.threshold)
+		p, err := r.ReadPacket()
+		if !reflect.DeepEqual(p, c.packet) || err != nil {
+			t.Errorf("ReadPacket() with threshold %d have: %#v, got: (%#v, %#v)", c.threshold, c.packet, p, err)
+		}
+	}
+}
+
+func TestCompressedPacketReaderSetCompressionThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	p := Packet{ID: 0x01, Data: []byte{0xff, 0xee, 0xdd}}
+
+	w := NewCompressedPacketWriter(&buf, -1)
+	if err := w.WritePacket(p); err != nil {
+		t.Fatalf("WritePacket failed: %#v", err)
+	}
+	w.SetCompressionThreshold(0)
+	if err := w.WritePacket(p); err != nil {
+		t.Fatalf("WritePacket failed: %#v", err)
+	}
+
+	r := NewCompressedPacketReader(&buf, -1)
+	got, err := r.ReadPacket()
+	if !reflect.DeepEqual(got, p) || err != nil {
+		t.Fatalf("ReadPacket() have: %#v, got: (%#v, %#v)", p, got, err)
+	}
+	r.SetCompressionThreshold(0)
+	got, err = r.ReadPacket()
+	if !reflect.DeepEqual(got, p) || err != nil {
+		t.Fatalf("ReadPacket() have: %#v, got: (%#v, %#v)", p, got, err)
+	}
+}
+
+func TestCompressedPacketReaderNegativeDataLength(t *testing.T) {
+	// A peer can encode dataLength as a negative VarInt (0xff 0xff 0xff
+	// 0xff 0x0f for -1). Without a lower-bound check alongside the
+	// existing upper-bound check, this reaches make([]byte, dataLength)
+	// and panics instead of returning an error.
+	var buf bytes.Buffer
+	pw := NewPacketWriter(&buf)
+	if err := pw.WritePacket(Packet{ID: -1, Data: []byte{0x00}}); err != nil {
+		t.Fatalf("WritePacket failed: %#v", err)
+	}
+
+	r := NewCompressedPacketReader(&buf, 0)
+	if _, err := r.ReadPacket(); errors.Cause(err) != errValueTooLarge {
+		t.Errorf("ReadPacket() with negative dataLength: have %#v, got %#v", errValueTooLarge, err)
+	}
+}
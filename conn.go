@@ -0,0 +1,132 @@
+package mcproto
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/njhanley/mcproto/cipher"
+)
+
+// packetReader is implemented by PacketReader and CompressedPacketReader.
+type packetReader interface {
+	ReadPacket() (Packet, error)
+
+	// leftover returns any bytes already read off the underlying reader
+	// into the packetReader's internal buffer but not yet consumed as
+	// packet data. A bufio.Reader fills its buffer from a single
+	// underlying Read, which can pull in bytes the peer sent for the
+	// next packet -- or the first bytes of a pipelined Set Compression
+	// followed immediately by Login Success -- well past the current
+	// packet's boundary, so a mode switch must recover them rather than
+	// silently drop them.
+	leftover() []byte
+}
+
+// packetWriter is implemented by PacketWriter and CompressedPacketWriter.
+type packetWriter interface {
+	WritePacket(Packet) error
+}
+
+// Conn wraps the two halves of a connection (typically a net.Conn) with
+// Minecraft's packet framing, so callers don't have to reimplement
+// length-prefix framing or the post-login compressed and encrypted wire
+// formats by hand. EnableCompression and EnableEncryption switch modes
+// mid-connection, as the login sequence requires.
+//
+// Both methods must be called between ReadPacket/WritePacket calls, not
+// while one is in progress. Bytes the peer sent after the switch point,
+// already pulled into the old packetReader's internal buffer by the time
+// the switch happens, are recovered via packetReader.leftover and
+// replayed through the new framing (and, for EnableEncryption, the new
+// cipher), so a mode switch is safe even when the peer pipelines the
+// packet that triggers it together with what follows.
+//
+// A Conn is not safe for concurrent use.
+type Conn struct {
+	r io.Reader
+	w io.Writer
+
+	pr packetReader
+	pw packetWriter
+
+	threshold int
+}
+
+// NewConn returns a Conn that reads and writes uncompressed, unencrypted
+// packets over rw.
+func NewConn(rw io.ReadWriter) *Conn {
+	c := &Conn{r: rw, w: rw, threshold: -1}
+	c.rebuildPacketIO()
+	return c
+}
+
+// ReadPacket reads and returns the next packet from the connection,
+// honoring whatever compression and encryption modes are currently in
+// effect.
+func (c *Conn) ReadPacket() (Packet, error) {
+	return c.pr.ReadPacket()
+}
+
+// WritePacket writes p to the connection, honoring whatever compression
+// and encryption modes are currently in effect.
+func (c *Conn) WritePacket(p Packet) error {
+	return c.pw.WritePacket(p)
+}
+
+// EnableCompression switches the connection to the post-login compressed
+// packet format: outgoing packets whose id and data are at least
+// threshold bytes long are compressed, and incoming packets are expected
+// in the same framing. A negative threshold disables compression.
+func (c *Conn) EnableCompression(threshold int) {
+	c.r = c.leftoverReader()
+	c.threshold = threshold
+	c.rebuildPacketIO()
+}
+
+// EnableEncryption switches the connection to encrypt every byte written
+// and decrypt every byte read from this point on, using AES-128/CFB8
+// with sharedSecret as both the key and the initial feedback register.
+// sharedSecret must be 16 bytes. Whatever compression mode is currently
+// in effect is preserved.
+func (c *Conn) EnableEncryption(sharedSecret []byte) error {
+	er, err := cipher.NewEncryptedReader(c.leftoverReader(), sharedSecret)
+	if err != nil {
+		return err
+	}
+	ew, err := cipher.NewEncryptedWriter(c.w, sharedSecret)
+	if err != nil {
+		return err
+	}
+
+	c.r, c.w = er, ew
+	c.rebuildPacketIO()
+	return nil
+}
+
+// leftoverReader returns c.r, prefixed with any bytes already buffered
+// but unconsumed by the current pr, so a caller about to replace c.r (or
+// just rebuild pr/pw over it with new framing) doesn't drop them.
+func (c *Conn) leftoverReader() io.Reader {
+	if c.pr == nil {
+		return c.r
+	}
+	if b := c.pr.leftover(); len(b) > 0 {
+		return io.MultiReader(bytes.NewReader(b), c.r)
+	}
+	return c.r
+}
+
+// rebuildPacketIO reconstructs pr/pw over the connection's current r/w,
+// picking the plain or compressed framing based on threshold. It is
+// called whenever r, w, or threshold changes, since PacketReader and
+// CompressedPacketReader buffer internally and can't have the reader or
+// writer they wrap swapped out from under them.
+func (c *Conn) rebuildPacketIO() {
+	if c.threshold < 0 {
+		c.pr = NewPacketReader(c.r)
+		c.pw = NewPacketWriter(c.w)
+		return
+	}
+	c.pr = NewCompressedPacketReader(c.r, c.threshold)
+	c.pw = NewCompressedPacketWriter(c.w, c.threshold)
+}
@@ -0,0 +1,94 @@
+package nbt
+
+import "github.com/pkg/errors"
+
+// newTag returns a zero-valued Tag of the concrete type identified by typ.
+func newTag(typ Type) Tag {
+	switch typ {
+	case EndType:
+		return &TagEnd{}
+	case ByteType:
+		return new(TagByte)
+	case ShortType:
+		return new(TagShort)
+	case IntType:
+		return new(TagInt)
+	case LongType:
+		return new(TagLong)
+	case FloatType:
+		return new(TagFloat)
+	case DoubleType:
+		return new(TagDouble)
+	case ByteArrayType:
+		return new(TagByteArray)
+	case StringType:
+		return new(TagString)
+	case ListType:
+		return new(TagList)
+	case CompoundType:
+		return new(TagCompound)
+	case IntArrayType:
+		return new(TagIntArray)
+	case LongArrayType:
+		return new(TagLongArray)
+	default:
+		return nil
+	}
+}
+
+// GetNBT reads a complete named tag (type byte, name, and payload) from
+// buf and returns the tag along with the number of bytes consumed. The
+// tag's name is discarded; callers that need it should read the type
+// byte and name themselves, or use a TagCompound's named entries.
+func GetNBT(buf []byte) (Tag, int, error) {
+	if len(buf) < 1 {
+		return nil, 0, errors.WithStack(errBufTooSmall)
+	}
+	typ := Type(buf[0])
+	n := 1
+
+	if typ == EndType {
+		return &TagEnd{}, n, nil
+	}
+
+	_, m, err := getModifiedUTF8(buf[n:])
+	if n += m; err != nil {
+		return nil, n, err
+	}
+
+	t := newTag(typ)
+	if t == nil {
+		return nil, n, errors.Errorf("nbt: unknown tag type %d", typ)
+	}
+	m, err = t.unmarshalPayload(buf[n:])
+	if n += m; err != nil {
+		return nil, n, err
+	}
+
+	return t, n, nil
+}
+
+// PutNBT writes t to buf as a named tag (type byte, name, and payload),
+// using an empty name, and returns the number of bytes written. Root
+// tags are conventionally unnamed in practice; use a TagCompound's named
+// entries for anything that needs an actual name on the wire.
+func PutNBT(buf []byte, t Tag) (n int, err error) {
+	if len(buf) < 1 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	buf[0] = byte(t.Type())
+	n = 1
+
+	if t.Type() == EndType {
+		return n, nil
+	}
+
+	m, err := putModifiedUTF8(buf[n:], "")
+	if n += m; err != nil {
+		return n, err
+	}
+
+	m, err = t.marshalPayload(buf[n:])
+	n += m
+	return n, err
+}
@@ -0,0 +1,39 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetPutNBT(t *testing.T) {
+	name := TagString("Steve")
+	health := TagFloat(20)
+	compound := &TagCompound{
+		{Name: "Name", Tag: &name},
+		{Name: "Health", Tag: &health},
+	}
+
+	buf := make([]byte, 64)
+	n, err := PutNBT(buf, compound)
+	if err != nil {
+		t.Fatalf("PutNBT failed: %#v", err)
+	}
+
+	got, m, err := GetNBT(buf[:n])
+	if err != nil {
+		t.Fatalf("GetNBT failed: %#v", err)
+	}
+	if m != n {
+		t.Errorf("consumed %d bytes, wrote %d", m, n)
+	}
+	if !reflect.DeepEqual(got, compound) {
+		t.Errorf("have: %#v, got: %#v", compound, got)
+	}
+}
+
+func TestPutNBTBufTooSmall(t *testing.T) {
+	var b TagByte
+	if _, err := PutNBT(nil, &b); err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+}
@@ -0,0 +1,319 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// getModifiedUTF8 and putModifiedUTF8 read/write NBT's length-prefixed
+// string encoding: a big-endian uint16 byte length followed by the bytes
+// themselves. Real NBT uses Java's modified UTF-8 (CESU-8 plus a
+// two-byte encoding of NUL); this implementation treats the bytes as
+// plain UTF-8, which round-trips correctly for every string without a
+// surrogate pair or embedded NUL.
+func getModifiedUTF8(buf []byte) (s string, n int, err error) {
+	if len(buf) < 2 {
+		return "", 0, errors.WithStack(errBufTooSmall)
+	}
+	length := int(binary.BigEndian.Uint16(buf))
+	n = 2
+	if len(buf) < n+length {
+		return "", len(buf), errors.WithStack(errBufTooSmall)
+	}
+	return string(buf[n : n+length]), n + length, nil
+}
+
+func putModifiedUTF8(buf []byte, s string) (n int, err error) {
+	if len(s) > math.MaxUint16 {
+		return 0, errors.WithStack(errValueTooLarge)
+	}
+	if len(buf) < 2 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint16(buf, uint16(len(s)))
+	n = 2
+	if len(buf) < n+len(s) {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	n += copy(buf[n:], s)
+	return n, nil
+}
+
+// TagString is a length-prefixed string.
+type TagString string
+
+func (TagString) Type() Type { return StringType }
+
+func (t TagString) marshalPayload(buf []byte) (int, error) {
+	return putModifiedUTF8(buf, string(t))
+}
+
+func (t *TagString) unmarshalPayload(buf []byte) (int, error) {
+	s, n, err := getModifiedUTF8(buf)
+	*t = TagString(s)
+	return n, err
+}
+
+// TagByteArray is a length-prefixed array of raw bytes.
+type TagByteArray []byte
+
+func (TagByteArray) Type() Type { return ByteArrayType }
+
+func (t TagByteArray) marshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(t)))
+	n = 4
+	if len(buf) < n+len(t) {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	n += copy(buf[n:], t)
+	return n, nil
+}
+
+func (t *TagByteArray) unmarshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	length := int(int32(binary.BigEndian.Uint32(buf)))
+	n = 4
+	if length < 0 || len(buf) < n+length {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	data := make([]byte, length)
+	n += copy(data, buf[n:n+length])
+	*t = data
+	return n, nil
+}
+
+// TagIntArray is a length-prefixed array of big-endian 32-bit integers.
+type TagIntArray []int32
+
+func (TagIntArray) Type() Type { return IntArrayType }
+
+func (t TagIntArray) marshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(t)))
+	n = 4
+	for _, v := range t {
+		if len(buf) < n+4 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		binary.BigEndian.PutUint32(buf[n:], uint32(v))
+		n += 4
+	}
+	return n, nil
+}
+
+func (t *TagIntArray) unmarshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	length := int(int32(binary.BigEndian.Uint32(buf)))
+	n = 4
+	// Bound length against what buf could actually hold before
+	// allocating, the same way TagByteArray does, so a forged length
+	// can't be used to make a multi-GB allocation from a few bytes of
+	// input.
+	if length < 0 || length > (len(buf)-n)/4 {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	data := make([]int32, length)
+	for i := range data {
+		if len(buf) < n+4 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		data[i] = int32(binary.BigEndian.Uint32(buf[n:]))
+		n += 4
+	}
+	*t = data
+	return n, nil
+}
+
+// TagLongArray is a length-prefixed array of big-endian 64-bit integers.
+type TagLongArray []int64
+
+func (TagLongArray) Type() Type { return LongArrayType }
+
+func (t TagLongArray) marshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(t)))
+	n = 4
+	for _, v := range t {
+		if len(buf) < n+8 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		binary.BigEndian.PutUint64(buf[n:], uint64(v))
+		n += 8
+	}
+	return n, nil
+}
+
+func (t *TagLongArray) unmarshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	length := int(int32(binary.BigEndian.Uint32(buf)))
+	n = 4
+	// See the matching check in TagIntArray.unmarshalPayload.
+	if length < 0 || length > (len(buf)-n)/8 {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	data := make([]int64, length)
+	for i := range data {
+		if len(buf) < n+8 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		data[i] = int64(binary.BigEndian.Uint64(buf[n:]))
+		n += 8
+	}
+	*t = data
+	return n, nil
+}
+
+// TagList is a homogeneous, length-prefixed list of unnamed tags.
+type TagList struct {
+	ElemType Type
+	Elems    []Tag
+}
+
+func (TagList) Type() Type { return ListType }
+
+func (t TagList) marshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 5 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	buf[0] = byte(t.ElemType)
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(t.Elems)))
+	n = 5
+
+	for _, e := range t.Elems {
+		m, err := e.marshalPayload(buf[n:])
+		if n += m; err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (t *TagList) unmarshalPayload(buf []byte) (n int, err error) {
+	if len(buf) < 5 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	elemType := Type(buf[0])
+	length := int(int32(binary.BigEndian.Uint32(buf[1:])))
+	n = 5
+	// Every element is at least 1 byte on the wire, so this is a loose
+	// bound, but it's enough to stop a forged length from allocating a
+	// huge []Tag before a single element has been read; see the matching
+	// check in TagByteArray.unmarshalPayload.
+	if length < 0 || length > len(buf)-n {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+
+	elems := make([]Tag, length)
+	for i := range elems {
+		e := newTag(elemType)
+		if e == nil {
+			return n, errors.Errorf("nbt: unknown tag type %d", elemType)
+		}
+		m, err := e.unmarshalPayload(buf[n:])
+		if n += m; err != nil {
+			return n, err
+		}
+		elems[i] = e
+	}
+
+	t.ElemType = elemType
+	t.Elems = elems
+	return n, nil
+}
+
+// CompoundEntry is one named tag inside a TagCompound.
+type CompoundEntry struct {
+	Name string
+	Tag  Tag
+}
+
+// TagCompound is an ordered sequence of named tags, terminated on the
+// wire by a TagEnd.
+type TagCompound []CompoundEntry
+
+func (TagCompound) Type() Type { return CompoundType }
+
+// Get returns the tag named name, if present.
+func (t TagCompound) Get(name string) (Tag, bool) {
+	for _, e := range t {
+		if e.Name == name {
+			return e.Tag, true
+		}
+	}
+	return nil, false
+}
+
+func (t TagCompound) marshalPayload(buf []byte) (n int, err error) {
+	for _, e := range t {
+		if len(buf) < n+1 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		buf[n] = byte(e.Tag.Type())
+		n++
+
+		m, err := putModifiedUTF8(buf[n:], e.Name)
+		if n += m; err != nil {
+			return n, err
+		}
+
+		m, err = e.Tag.marshalPayload(buf[n:])
+		if n += m; err != nil {
+			return n, err
+		}
+	}
+
+	if len(buf) < n+1 {
+		return len(buf), errors.WithStack(errBufTooSmall)
+	}
+	buf[n] = byte(EndType)
+	n++
+	return n, nil
+}
+
+func (t *TagCompound) unmarshalPayload(buf []byte) (n int, err error) {
+	var entries TagCompound
+	for {
+		if len(buf) < n+1 {
+			return len(buf), errors.WithStack(errBufTooSmall)
+		}
+		typ := Type(buf[n])
+		n++
+		if typ == EndType {
+			break
+		}
+
+		name, m, err := getModifiedUTF8(buf[n:])
+		if n += m; err != nil {
+			return n, err
+		}
+
+		tag := newTag(typ)
+		if tag == nil {
+			return n, errors.Errorf("nbt: unknown tag type %d", typ)
+		}
+		m, err = tag.unmarshalPayload(buf[n:])
+		if n += m; err != nil {
+			return n, err
+		}
+
+		entries = append(entries, CompoundEntry{Name: name, Tag: tag})
+	}
+
+	*t = entries
+	return n, nil
+}
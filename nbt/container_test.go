@@ -0,0 +1,99 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestContainerTagRoundTrip(t *testing.T) {
+	str := TagString("hello, world")
+	byteArray := TagByteArray{1, 2, 3}
+	intArray := TagIntArray{1, -2, 3}
+	longArray := TagLongArray{1, -2, 3}
+	list := TagList{ElemType: ByteType, Elems: []Tag{
+		func() Tag { v := TagByte(1); return &v }(),
+		func() Tag { v := TagByte(2); return &v }(),
+	}}
+	name := TagString("Steve")
+	compound := TagCompound{{Name: "Name", Tag: &name}}
+
+	cases := []Tag{&str, &byteArray, &intArray, &longArray, &list, &compound}
+
+	for _, tag := range cases {
+		buf := make([]byte, 256)
+		n, err := tag.marshalPayload(buf)
+		if err != nil {
+			t.Errorf("%T: marshalPayload failed: %#v", tag, err)
+			continue
+		}
+
+		got := newTag(tag.Type())
+		m, err := got.unmarshalPayload(buf[:n])
+		if err != nil {
+			t.Errorf("%T: unmarshalPayload failed: %#v", tag, err)
+			continue
+		}
+		if m != n {
+			t.Errorf("%T: consumed %d bytes, wrote %d", tag, m, n)
+		}
+		if !reflect.DeepEqual(got, tag) {
+			t.Errorf("%T: have %#v, got %#v", tag, tag, got)
+		}
+	}
+}
+
+// TestContainerForgedLengthRejected checks that a forged length prefix
+// claiming far more elements than the buffer could hold is rejected
+// before the corresponding make() call, rather than attempting a huge
+// allocation.
+func TestContainerForgedLengthRejected(t *testing.T) {
+	lengthPrefix := func(n uint32) []byte {
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, n)
+		return buf
+	}
+	listBuf := append([]byte{byte(ByteType)}, lengthPrefix(0x7fffffff)...)
+
+	cases := []struct {
+		name string
+		tag  Tag
+		buf  []byte
+	}{
+		{"TagIntArray", &TagIntArray{}, lengthPrefix(0x7fffffff)},
+		{"TagLongArray", &TagLongArray{}, lengthPrefix(0x7fffffff)},
+		{"TagList", &TagList{}, listBuf},
+	}
+
+	for _, c := range cases {
+		if _, err := c.tag.unmarshalPayload(c.buf); err == nil {
+			t.Errorf("%s: expected an error for a length the buffer can't hold, got nil", c.name)
+		}
+	}
+}
+
+// TestContainerUnknownNestedTagType checks that an unrecognized tag type
+// byte nested inside a TagList or TagCompound (rather than at the root,
+// where GetNBT already catches it) returns an error instead of panicking
+// on newTag's nil return.
+func TestContainerUnknownNestedTagType(t *testing.T) {
+	const unknownType = 0x7f
+
+	list := TagList{}
+	listBuf := append([]byte{unknownType}, make([]byte, 5)...) // elemType, length, one element byte
+	binary.BigEndian.PutUint32(listBuf[1:], 1)
+	if _, err := list.unmarshalPayload(listBuf); err == nil {
+		t.Error("TagList: expected an error for an unknown element type, got nil")
+	}
+
+	compound := TagCompound{}
+	nameBuf := make([]byte, 64)
+	m, err := putModifiedUTF8(nameBuf, "name")
+	if err != nil {
+		t.Fatalf("putModifiedUTF8 failed: %#v", err)
+	}
+	compoundBuf := append([]byte{unknownType}, nameBuf[:m]...)
+	if _, err := compound.unmarshalPayload(compoundBuf); err == nil {
+		t.Error("TagCompound: expected an error for an unknown entry type, got nil")
+	}
+}
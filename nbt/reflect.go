@@ -0,0 +1,261 @@
+package nbt
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Marshal encodes v, which must be a struct or a pointer to one, as a
+// TagCompound and returns its on-wire representation (as written by
+// PutNBT). Exported fields are encoded under the name given by their
+// `nbt:"name"` tag, or their Go field name if no tag is present; fields
+// tagged `nbt:"-"` are skipped.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	compound, err := structToCompound(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, SizeOf(&compound))
+	n, err := PutNBT(buf, &compound)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// Unmarshal decodes a named tag from data, which must encode a
+// TagCompound, into v, which must be a non-nil pointer to a struct.
+func Unmarshal(data []byte, v interface{}) error {
+	tag, _, err := GetNBT(data)
+	if err != nil {
+		return err
+	}
+	compound, ok := tag.(*TagCompound)
+	if !ok {
+		return errors.Errorf("nbt: expected a compound tag, got %T", tag)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("nbt: Unmarshal requires a non-nil pointer")
+	}
+	return compoundToStruct(*compound, rv.Elem())
+}
+
+func fieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("nbt")
+	if tag == "-" {
+		return "", false
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return f.Name, true
+}
+
+func structToCompound(rv reflect.Value) (TagCompound, error) {
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.Errorf("nbt: expected a struct, got %s", rv.Kind())
+	}
+
+	var entries TagCompound
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+
+		tag, err := valueToTag(rv.Field(i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "nbt: field %s", f.Name)
+		}
+		entries = append(entries, CompoundEntry{Name: name, Tag: tag})
+	}
+	return entries, nil
+}
+
+func valueToTag(v reflect.Value) (Tag, error) {
+	switch v.Kind() {
+	case reflect.Int8:
+		t := TagByte(v.Int())
+		return &t, nil
+	case reflect.Int16:
+		t := TagShort(v.Int())
+		return &t, nil
+	case reflect.Int32:
+		t := TagInt(v.Int())
+		return &t, nil
+	case reflect.Int64, reflect.Int:
+		t := TagLong(v.Int())
+		return &t, nil
+	case reflect.Float32:
+		t := TagFloat(v.Float())
+		return &t, nil
+	case reflect.Float64:
+		t := TagDouble(v.Float())
+		return &t, nil
+	case reflect.String:
+		t := TagString(v.String())
+		return &t, nil
+	case reflect.Bool:
+		var t TagByte
+		if v.Bool() {
+			t = 1
+		}
+		return &t, nil
+	case reflect.Struct:
+		c, err := structToCompound(v)
+		return &c, err
+	case reflect.Slice:
+		switch v.Type().Elem().Kind() {
+		case reflect.Uint8:
+			b := TagByteArray(v.Bytes())
+			return &b, nil
+		case reflect.Int32:
+			arr := make(TagIntArray, v.Len())
+			for i := range arr {
+				arr[i] = int32(v.Index(i).Int())
+			}
+			return &arr, nil
+		case reflect.Int64:
+			arr := make(TagLongArray, v.Len())
+			for i := range arr {
+				arr[i] = v.Index(i).Int()
+			}
+			return &arr, nil
+		default:
+			list := TagList{}
+			for i := 0; i < v.Len(); i++ {
+				elem, err := valueToTag(v.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				list.ElemType = elem.Type()
+				list.Elems = append(list.Elems, elem)
+			}
+			return &list, nil
+		}
+	default:
+		return nil, errors.Errorf("nbt: unsupported type %s", v.Type())
+	}
+}
+
+func compoundToStruct(c TagCompound, rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return errors.Errorf("nbt: expected a struct, got %s", rv.Kind())
+	}
+
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+
+		tag, ok := c.Get(name)
+		if !ok {
+			continue
+		}
+		if err := tagToValue(tag, rv.Field(i)); err != nil {
+			return errors.Wrapf(err, "nbt: field %s", f.Name)
+		}
+	}
+	return nil
+}
+
+func tagToValue(tag Tag, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		switch t := tag.(type) {
+		case *TagByte:
+			v.SetInt(int64(*t))
+		case *TagShort:
+			v.SetInt(int64(*t))
+		case *TagInt:
+			v.SetInt(int64(*t))
+		case *TagLong:
+			v.SetInt(int64(*t))
+		default:
+			return errors.Errorf("nbt: cannot decode %T into %s", tag, v.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+		switch t := tag.(type) {
+		case *TagFloat:
+			v.SetFloat(float64(*t))
+		case *TagDouble:
+			v.SetFloat(float64(*t))
+		default:
+			return errors.Errorf("nbt: cannot decode %T into %s", tag, v.Type())
+		}
+	case reflect.String:
+		s, ok := tag.(*TagString)
+		if !ok {
+			return errors.Errorf("nbt: cannot decode %T into string", tag)
+		}
+		v.SetString(string(*s))
+	case reflect.Bool:
+		b, ok := tag.(*TagByte)
+		if !ok {
+			return errors.Errorf("nbt: cannot decode %T into bool", tag)
+		}
+		v.SetBool(*b != 0)
+	case reflect.Struct:
+		c, ok := tag.(*TagCompound)
+		if !ok {
+			return errors.Errorf("nbt: cannot decode %T into struct", tag)
+		}
+		return compoundToStruct(*c, v)
+	case reflect.Slice:
+		switch v.Type().Elem().Kind() {
+		case reflect.Uint8:
+			b, ok := tag.(*TagByteArray)
+			if !ok {
+				return errors.Errorf("nbt: cannot decode %T into []byte", tag)
+			}
+			v.SetBytes([]byte(*b))
+		case reflect.Int32:
+			a, ok := tag.(*TagIntArray)
+			if !ok {
+				return errors.Errorf("nbt: cannot decode %T into []int32", tag)
+			}
+			v.Set(reflect.ValueOf([]int32(*a)))
+		case reflect.Int64:
+			a, ok := tag.(*TagLongArray)
+			if !ok {
+				return errors.Errorf("nbt: cannot decode %T into []int64", tag)
+			}
+			v.Set(reflect.ValueOf([]int64(*a)))
+		default:
+			list, ok := tag.(*TagList)
+			if !ok {
+				return errors.Errorf("nbt: cannot decode %T into %s", tag, v.Type())
+			}
+			out := reflect.MakeSlice(v.Type(), len(list.Elems), len(list.Elems))
+			for i, e := range list.Elems {
+				if err := tagToValue(e, out.Index(i)); err != nil {
+					return err
+				}
+			}
+			v.Set(out)
+		}
+	default:
+		return errors.Errorf("nbt: unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package nbt
+
+import "testing"
+
+type player struct {
+	Name   string
+	Health float32
+	Pos    []int32 `nbt:"Position"`
+	hidden string
+	Ignore string `nbt:"-"`
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	p := player{
+		Name:   "Steve",
+		Health: 20,
+		Pos:    []int32{0, 64, 0},
+		Ignore: "not written",
+	}
+
+	data, err := Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %#v", err)
+	}
+
+	var got player
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %#v", err)
+	}
+
+	if got.Name != p.Name || got.Health != p.Health {
+		t.Errorf("have: %+v, got: %+v", p, got)
+	}
+	if len(got.Pos) != len(p.Pos) {
+		t.Fatalf("have Pos: %v, got: %v", p.Pos, got.Pos)
+	}
+	for i := range p.Pos {
+		if got.Pos[i] != p.Pos[i] {
+			t.Errorf("have Pos: %v, got: %v", p.Pos, got.Pos)
+			break
+		}
+	}
+	if got.Ignore != "" {
+		t.Errorf("expected Ignore to be skipped, got %q", got.Ignore)
+	}
+}
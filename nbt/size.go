@@ -0,0 +1,56 @@
+package nbt
+
+// SizeOf returns the number of bytes PutNBT would write for t, so a
+// caller can preallocate an exact-size buffer instead of guessing or
+// retrying with a larger one.
+func SizeOf(t Tag) int {
+	if t.Type() == EndType {
+		return 1
+	}
+	return 1 + sizeModifiedUTF8("") + sizePayload(t)
+}
+
+func sizeModifiedUTF8(s string) int {
+	return 2 + len(s)
+}
+
+func sizePayload(t Tag) int {
+	switch v := t.(type) {
+	case *TagEnd:
+		return 0
+	case *TagByte:
+		return 1
+	case *TagShort:
+		return 2
+	case *TagInt:
+		return 4
+	case *TagLong:
+		return 8
+	case *TagFloat:
+		return 4
+	case *TagDouble:
+		return 8
+	case *TagByteArray:
+		return 4 + len(*v)
+	case *TagString:
+		return sizeModifiedUTF8(string(*v))
+	case *TagIntArray:
+		return 4 + 4*len(*v)
+	case *TagLongArray:
+		return 4 + 8*len(*v)
+	case *TagList:
+		n := 5
+		for _, e := range v.Elems {
+			n += sizePayload(e)
+		}
+		return n
+	case *TagCompound:
+		n := 1 // TagEnd terminator
+		for _, e := range *v {
+			n += 1 + sizeModifiedUTF8(e.Name) + sizePayload(e.Tag)
+		}
+		return n
+	default:
+		return 0
+	}
+}
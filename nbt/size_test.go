@@ -0,0 +1,21 @@
+package nbt
+
+import "testing"
+
+func TestSizeOf(t *testing.T) {
+	name := TagString("Steve")
+	health := TagFloat(20)
+	compound := &TagCompound{
+		{Name: "Name", Tag: &name},
+		{Name: "Health", Tag: &health},
+	}
+
+	buf := make([]byte, SizeOf(compound))
+	n, err := PutNBT(buf, compound)
+	if err != nil {
+		t.Fatalf("PutNBT failed: %#v", err)
+	}
+	if n != len(buf) {
+		t.Errorf("SizeOf: have %d, got %d", n, len(buf))
+	}
+}
@@ -0,0 +1,389 @@
+package nbt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// NBTDecoder reads a sequence of NBT tags from an io.Reader, decoding
+// each tag's payload as it is read rather than buffering the whole tag
+// tree up front. This keeps memory proportional to the current tag
+// rather than the whole blob, which matters for chunk sections and
+// other large payloads.
+type NBTDecoder struct {
+	r       *bufio.Reader
+	network bool
+}
+
+// NewNBTDecoder returns an NBTDecoder reading from r. If network is
+// true, each root tag read by Decode is assumed to omit its name,
+// matching the variant used for chunk data and other payloads sent over
+// the network in modern versions; if false, each root tag's name is read
+// as in the Java edition file format. Names of entries inside a
+// TagCompound are read in both modes.
+func NewNBTDecoder(r io.Reader, network bool) *NBTDecoder {
+	return &NBTDecoder{r: bufio.NewReader(r), network: network}
+}
+
+// Decode reads one tag from the stream and returns it along with its
+// name. The name is always "" when the decoder was constructed with
+// network set to true.
+func (d *NBTDecoder) Decode() (Tag, string, error) {
+	typ, err := d.readType()
+	if err != nil {
+		return nil, "", err
+	}
+	if typ == EndType {
+		return &TagEnd{}, "", nil
+	}
+
+	var name string
+	if !d.network {
+		if name, err = d.readModifiedUTF8(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	t := newTag(typ)
+	if t == nil {
+		return nil, name, errors.Errorf("nbt: unknown tag type %d", typ)
+	}
+	if err := d.readPayload(t); err != nil {
+		return nil, name, err
+	}
+	return t, name, nil
+}
+
+func (d *NBTDecoder) readType() (Type, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return Type(b), nil
+}
+
+func (d *NBTDecoder) readInt32() (int32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int32(binary.BigEndian.Uint32(buf[:])), nil
+}
+
+func (d *NBTDecoder) readModifiedUTF8() (string, error) {
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(d.r, lenBuf[:]); err != nil {
+		return "", errors.WithStack(err)
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(lenBuf[:]))
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(buf), nil
+}
+
+// readPayload reads t's payload from the stream, recursing into
+// TagList/TagCompound children. t must be one of the concrete *Tag*
+// pointer types returned by newTag.
+func (d *NBTDecoder) readPayload(t Tag) error {
+	switch v := t.(type) {
+	case *TagByte:
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		*v = TagByte(b)
+	case *TagShort:
+		var buf [2]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return errors.WithStack(err)
+		}
+		*v = TagShort(binary.BigEndian.Uint16(buf[:]))
+	case *TagInt:
+		n, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		*v = TagInt(n)
+	case *TagLong:
+		var buf [8]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return errors.WithStack(err)
+		}
+		*v = TagLong(binary.BigEndian.Uint64(buf[:]))
+	case *TagFloat:
+		var buf [4]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return errors.WithStack(err)
+		}
+		*v = TagFloat(math.Float32frombits(binary.BigEndian.Uint32(buf[:])))
+	case *TagDouble:
+		var buf [8]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return errors.WithStack(err)
+		}
+		*v = TagDouble(math.Float64frombits(binary.BigEndian.Uint64(buf[:])))
+	case *TagByteArray:
+		length, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return errors.WithStack(errValueTooLarge)
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return errors.WithStack(err)
+		}
+		*v = buf
+	case *TagString:
+		s, err := d.readModifiedUTF8()
+		if err != nil {
+			return err
+		}
+		*v = TagString(s)
+	case *TagIntArray:
+		length, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return errors.WithStack(errValueTooLarge)
+		}
+		arr := make([]int32, length)
+		for i := range arr {
+			if arr[i], err = d.readInt32(); err != nil {
+				return err
+			}
+		}
+		*v = arr
+	case *TagLongArray:
+		length, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return errors.WithStack(errValueTooLarge)
+		}
+		arr := make([]int64, length)
+		for i := range arr {
+			var buf [8]byte
+			if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+				return errors.WithStack(err)
+			}
+			arr[i] = int64(binary.BigEndian.Uint64(buf[:]))
+		}
+		*v = arr
+	case *TagList:
+		elemType, err := d.readType()
+		if err != nil {
+			return err
+		}
+		length, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return errors.WithStack(errValueTooLarge)
+		}
+		elems := make([]Tag, length)
+		for i := range elems {
+			e := newTag(elemType)
+			if e == nil {
+				return errors.Errorf("nbt: unknown tag type %d", elemType)
+			}
+			if err := d.readPayload(e); err != nil {
+				return err
+			}
+			elems[i] = e
+		}
+		v.ElemType = elemType
+		v.Elems = elems
+	case *TagCompound:
+		var entries TagCompound
+		for {
+			typ, err := d.readType()
+			if err != nil {
+				return err
+			}
+			if typ == EndType {
+				break
+			}
+			name, err := d.readModifiedUTF8()
+			if err != nil {
+				return err
+			}
+			e := newTag(typ)
+			if e == nil {
+				return errors.Errorf("nbt: unknown tag type %d", typ)
+			}
+			if err := d.readPayload(e); err != nil {
+				return err
+			}
+			entries = append(entries, CompoundEntry{Name: name, Tag: e})
+		}
+		*v = entries
+	case *TagEnd:
+	default:
+		return errors.Errorf("nbt: unsupported tag type %T", t)
+	}
+	return nil
+}
+
+// NBTEncoder writes a sequence of NBT tags to an io.Writer, writing
+// each tag's payload as it goes rather than building the whole encoded
+// form in memory first.
+type NBTEncoder struct {
+	w       io.Writer
+	network bool
+}
+
+// NewNBTEncoder returns an NBTEncoder writing to w. If network is
+// true, the name of each root tag written by Encode is omitted on the
+// wire, matching the variant used for chunk data and other payloads sent
+// over the network in modern versions; if false, each root tag is
+// written with its name as in the Java edition file format. Names of
+// entries inside a TagCompound are written in both modes.
+func NewNBTEncoder(w io.Writer, network bool) *NBTEncoder {
+	return &NBTEncoder{w: w, network: network}
+}
+
+// Encode writes t to the stream under name. name is ignored when the
+// encoder was constructed with network set to true.
+func (e *NBTEncoder) Encode(name string, t Tag) error {
+	if err := e.writeType(t.Type()); err != nil {
+		return err
+	}
+	if t.Type() == EndType {
+		return nil
+	}
+	if !e.network {
+		if err := e.writeModifiedUTF8(name); err != nil {
+			return err
+		}
+	}
+	return e.writePayload(t)
+}
+
+func (e *NBTEncoder) writeType(typ Type) error {
+	_, err := e.w.Write([]byte{byte(typ)})
+	return errors.WithStack(err)
+}
+
+func (e *NBTEncoder) writeInt32(v int32) error {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(v))
+	_, err := e.w.Write(buf[:])
+	return errors.WithStack(err)
+}
+
+func (e *NBTEncoder) writeModifiedUTF8(s string) error {
+	if len(s) > math.MaxUint16 {
+		return errors.WithStack(errValueTooLarge)
+	}
+	var lenBuf [2]byte
+	binary.BigEndian.PutUint16(lenBuf[:], uint16(len(s)))
+	if _, err := e.w.Write(lenBuf[:]); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := io.WriteString(e.w, s)
+	return errors.WithStack(err)
+}
+
+// writePayload writes t's payload to the stream, recursing into
+// TagList/TagCompound children. t must be one of the concrete *Tag*
+// pointer types, as returned by newTag, since those are the only ones
+// satisfying the Tag interface.
+func (e *NBTEncoder) writePayload(t Tag) error {
+	switch v := t.(type) {
+	case *TagByte:
+		_, err := e.w.Write([]byte{byte(*v)})
+		return errors.WithStack(err)
+	case *TagShort:
+		var buf [2]byte
+		binary.BigEndian.PutUint16(buf[:], uint16(*v))
+		_, err := e.w.Write(buf[:])
+		return errors.WithStack(err)
+	case *TagInt:
+		return e.writeInt32(int32(*v))
+	case *TagLong:
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(*v))
+		_, err := e.w.Write(buf[:])
+		return errors.WithStack(err)
+	case *TagFloat:
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], math.Float32bits(float32(*v)))
+		_, err := e.w.Write(buf[:])
+		return errors.WithStack(err)
+	case *TagDouble:
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], math.Float64bits(float64(*v)))
+		_, err := e.w.Write(buf[:])
+		return errors.WithStack(err)
+	case *TagByteArray:
+		if err := e.writeInt32(int32(len(*v))); err != nil {
+			return err
+		}
+		_, err := e.w.Write(*v)
+		return errors.WithStack(err)
+	case *TagString:
+		return e.writeModifiedUTF8(string(*v))
+	case *TagIntArray:
+		if err := e.writeInt32(int32(len(*v))); err != nil {
+			return err
+		}
+		for _, x := range *v {
+			if err := e.writeInt32(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *TagLongArray:
+		if err := e.writeInt32(int32(len(*v))); err != nil {
+			return err
+		}
+		for _, x := range *v {
+			var buf [8]byte
+			binary.BigEndian.PutUint64(buf[:], uint64(x))
+			if _, err := e.w.Write(buf[:]); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	case *TagList:
+		if err := e.writeType(v.ElemType); err != nil {
+			return err
+		}
+		if err := e.writeInt32(int32(len(v.Elems))); err != nil {
+			return err
+		}
+		for _, elem := range v.Elems {
+			if err := e.writePayload(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *TagCompound:
+		for _, entry := range *v {
+			if err := e.writeType(entry.Tag.Type()); err != nil {
+				return err
+			}
+			if err := e.writeModifiedUTF8(entry.Name); err != nil {
+				return err
+			}
+			if err := e.writePayload(entry.Tag); err != nil {
+				return err
+			}
+		}
+		return e.writeType(EndType)
+	case *TagEnd:
+		return nil
+	default:
+		return errors.Errorf("nbt: unsupported tag type %T", t)
+	}
+}
@@ -0,0 +1,37 @@
+package nbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	name := TagString("Steve")
+	health := TagFloat(20)
+	compound := &TagCompound{
+		{Name: "Name", Tag: &name},
+		{Name: "Health", Tag: &health},
+	}
+
+	for _, network := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := NewNBTEncoder(&buf, network).Encode("root", compound); err != nil {
+			t.Fatalf("network=%v: Encode failed: %#v", network, err)
+		}
+
+		got, name, err := NewNBTDecoder(&buf, network).Decode()
+		if err != nil {
+			t.Fatalf("network=%v: Decode failed: %#v", network, err)
+		}
+		if network && name != "" {
+			t.Errorf("network=%v: expected empty name, got %q", network, name)
+		}
+		if !network && name != "root" {
+			t.Errorf("network=%v: have name %q, got %q", network, "root", name)
+		}
+		if !reflect.DeepEqual(got, compound) {
+			t.Errorf("network=%v: have %#v, got %#v", network, compound, got)
+		}
+	}
+}
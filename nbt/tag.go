@@ -0,0 +1,177 @@
+// Package nbt implements Minecraft's Named Binary Tag format, used to
+// embed structured data (slot data, chunk sections, chat components) in
+// otherwise flat packets. The wire format is big-endian throughout.
+package nbt
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Type identifies the concrete kind of a Tag on the wire.
+type Type byte
+
+const (
+	EndType Type = iota
+	ByteType
+	ShortType
+	IntType
+	LongType
+	FloatType
+	DoubleType
+	ByteArrayType
+	StringType
+	ListType
+	CompoundType
+	IntArrayType
+	LongArrayType
+)
+
+var (
+	errBufTooSmall   = errors.New("buf too small")
+	errValueTooLarge = errors.New("value too large")
+)
+
+// Tag is implemented by every NBT tag type. Its payload methods read and
+// write only the tag's value, not its type byte or name, which GetNBT and
+// PutNBT (and TagList/TagCompound, for their children) handle themselves.
+type Tag interface {
+	Type() Type
+	marshalPayload(buf []byte) (int, error)
+	unmarshalPayload(buf []byte) (int, error)
+}
+
+// TagEnd terminates a TagCompound. It has no payload.
+type TagEnd struct{}
+
+func (TagEnd) Type() Type                               { return EndType }
+func (TagEnd) marshalPayload(buf []byte) (int, error)   { return 0, nil }
+func (*TagEnd) unmarshalPayload(buf []byte) (int, error) { return 0, nil }
+
+// TagByte is a single signed byte.
+type TagByte int8
+
+func (TagByte) Type() Type { return ByteType }
+
+func (t TagByte) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 1 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	buf[0] = byte(t)
+	return 1, nil
+}
+
+func (t *TagByte) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 1 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagByte(buf[0])
+	return 1, nil
+}
+
+// TagShort is a big-endian signed 16-bit integer.
+type TagShort int16
+
+func (TagShort) Type() Type { return ShortType }
+
+func (t TagShort) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 2 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint16(buf, uint16(t))
+	return 2, nil
+}
+
+func (t *TagShort) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 2 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagShort(binary.BigEndian.Uint16(buf))
+	return 2, nil
+}
+
+// TagInt is a big-endian signed 32-bit integer.
+type TagInt int32
+
+func (TagInt) Type() Type { return IntType }
+
+func (t TagInt) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(t))
+	return 4, nil
+}
+
+func (t *TagInt) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagInt(binary.BigEndian.Uint32(buf))
+	return 4, nil
+}
+
+// TagLong is a big-endian signed 64-bit integer.
+type TagLong int64
+
+func (TagLong) Type() Type { return LongType }
+
+func (t TagLong) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 8 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint64(buf, uint64(t))
+	return 8, nil
+}
+
+func (t *TagLong) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 8 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagLong(binary.BigEndian.Uint64(buf))
+	return 8, nil
+}
+
+// TagFloat is a big-endian IEEE 754 single-precision float.
+type TagFloat float32
+
+func (TagFloat) Type() Type { return FloatType }
+
+func (t TagFloat) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, math.Float32bits(float32(t)))
+	return 4, nil
+}
+
+func (t *TagFloat) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagFloat(math.Float32frombits(binary.BigEndian.Uint32(buf)))
+	return 4, nil
+}
+
+// TagDouble is a big-endian IEEE 754 double-precision float.
+type TagDouble float64
+
+func (TagDouble) Type() Type { return DoubleType }
+
+func (t TagDouble) marshalPayload(buf []byte) (int, error) {
+	if len(buf) < 8 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint64(buf, math.Float64bits(float64(t)))
+	return 8, nil
+}
+
+func (t *TagDouble) unmarshalPayload(buf []byte) (int, error) {
+	if len(buf) < 8 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	*t = TagDouble(math.Float64frombits(binary.BigEndian.Uint64(buf)))
+	return 8, nil
+}
@@ -0,0 +1,39 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScalarTagRoundTrip(t *testing.T) {
+	cases := []Tag{
+		func() Tag { v := TagByte(-12); return &v }(),
+		func() Tag { v := TagShort(-1234); return &v }(),
+		func() Tag { v := TagInt(-123456); return &v }(),
+		func() Tag { v := TagLong(-123456789012); return &v }(),
+		func() Tag { v := TagFloat(3.5); return &v }(),
+		func() Tag { v := TagDouble(-2.25); return &v }(),
+	}
+
+	for _, tag := range cases {
+		buf := make([]byte, 16)
+		n, err := tag.marshalPayload(buf)
+		if err != nil {
+			t.Errorf("%T: marshalPayload failed: %#v", tag, err)
+			continue
+		}
+
+		got := newTag(tag.Type())
+		m, err := got.unmarshalPayload(buf[:n])
+		if err != nil {
+			t.Errorf("%T: unmarshalPayload failed: %#v", tag, err)
+			continue
+		}
+		if m != n {
+			t.Errorf("%T: consumed %d bytes, wrote %d", tag, m, n)
+		}
+		if !reflect.DeepEqual(got, tag) {
+			t.Errorf("%T: have %#v, got %#v", tag, tag, got)
+		}
+	}
+}
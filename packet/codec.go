@@ -0,0 +1,504 @@
+package packet
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/njhanley/mcproto"
+)
+
+// bitSlot is one struct field packed into a shared 64-bit wire word by a
+// "bitfield" tag, identified by its index within the struct.
+type bitSlot struct {
+	index  int
+	size   uint
+	offset uint
+}
+
+// fieldOp is one compiled step of a type descriptor: how to (de)serialize
+// a single tagged struct field, identified by its index within the
+// struct, using the same tag grammar mcprotogen consumes (see the
+// package doc comment).
+type fieldOp struct {
+	index   int
+	kind    string
+	max     int64
+	present int    // field index of the sibling bool, for "optional"
+	elem    string // pointee/element kind, for "optional" and "array"
+	bits    []bitSlot
+}
+
+// typeDescriptor is the compiled form of a struct's mcproto tags, cached
+// per reflect.Type so repeated Marshal/Unmarshal/SizeOf calls for the
+// same type never re-walk the struct with reflection.
+type typeDescriptor struct {
+	fields []fieldOp
+}
+
+// positionLen is the on-wire size of an mcproto.Position, mirroring the
+// unexported constant of the same name in the mcproto package.
+const positionLen = 8
+
+// scalarKinds are the tag kinds a "bitfield" or "array" slot can't be,
+// and the only kinds "optional"/"array" will accept as an elem=.
+var scalarKinds = map[string]bool{
+	"varint": true, "varlong": true, "string": true, "bool": true,
+	"byte": true, "short": true, "int": true, "long": true,
+	"float": true, "double": true, "position": true, "uuid": true,
+}
+
+var descriptorCache sync.Map // reflect.Type -> *typeDescriptor
+
+func descriptorFor(t reflect.Type) (*typeDescriptor, error) {
+	if d, ok := descriptorCache.Load(t); ok {
+		return d.(*typeDescriptor), nil
+	}
+
+	d, err := compileDescriptor(t)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := descriptorCache.LoadOrStore(t, d)
+	return actual.(*typeDescriptor), nil
+}
+
+func compileDescriptor(t reflect.Type) (*typeDescriptor, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("packet: %s is not a struct", t)
+	}
+
+	var d typeDescriptor
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("mcproto")
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		op := fieldOp{index: i, kind: parts[0]}
+		var sawPresent, sawElem, sawLen, sawSize, sawOffset bool
+		var size, offset int64
+		for _, opt := range parts[1:] {
+			kv := strings.SplitN(opt, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("packet: %s.%s: malformed tag option %q", t, f.Name, opt)
+			}
+			switch kv[0] {
+			case "max":
+				n, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, errors.Errorf("packet: %s.%s: invalid max %q", t, f.Name, kv[1])
+				}
+				op.max = n
+			case "present":
+				pf, ok := t.FieldByName(kv[1])
+				if !ok {
+					return nil, errors.Errorf("packet: %s.%s: unknown present field %q", t, f.Name, kv[1])
+				}
+				op.present = pf.Index[0]
+				sawPresent = true
+			case "elem":
+				if !scalarKinds[kv[1]] {
+					return nil, errors.Errorf("packet: %s.%s: unknown elem kind %q", t, f.Name, kv[1])
+				}
+				op.elem = kv[1]
+				sawElem = true
+			case "len":
+				if kv[1] != "varint" {
+					return nil, errors.Errorf("packet: %s.%s: unsupported array len kind %q", t, f.Name, kv[1])
+				}
+				sawLen = true
+			case "size":
+				n, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, errors.Errorf("packet: %s.%s: invalid size %q", t, f.Name, kv[1])
+				}
+				size = n
+				sawSize = true
+			case "offset":
+				n, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, errors.Errorf("packet: %s.%s: invalid offset %q", t, f.Name, kv[1])
+				}
+				offset = n
+				sawOffset = true
+			default:
+				return nil, errors.Errorf("packet: %s.%s: unknown tag option %q", t, f.Name, kv[0])
+			}
+		}
+
+		switch op.kind {
+		case "optional":
+			if !sawPresent {
+				return nil, errors.Errorf("packet: %s.%s: optional field requires present=Field", t, f.Name)
+			}
+			if !sawElem {
+				return nil, errors.Errorf("packet: %s.%s: optional field requires elem=kind", t, f.Name)
+			}
+			if f.Type.Kind() != reflect.Ptr {
+				return nil, errors.Errorf("packet: %s.%s: optional field must be a pointer", t, f.Name)
+			}
+		case "array":
+			if !sawLen {
+				return nil, errors.Errorf("packet: %s.%s: array field requires len=varint", t, f.Name)
+			}
+			if !sawElem {
+				return nil, errors.Errorf("packet: %s.%s: array field requires elem=kind", t, f.Name)
+			}
+			if f.Type.Kind() != reflect.Slice {
+				return nil, errors.Errorf("packet: %s.%s: array field must be a slice", t, f.Name)
+			}
+		case "bitfield":
+			if !sawSize || !sawOffset {
+				return nil, errors.Errorf("packet: %s.%s: bitfield field requires size= and offset=", t, f.Name)
+			}
+			if size <= 0 || offset < 0 || size+offset > 64 {
+				return nil, errors.Errorf("packet: %s.%s: bitfield size/offset must fit in a 64-bit word", t, f.Name)
+			}
+			slot := bitSlot{index: i, size: uint(size), offset: uint(offset)}
+			// Bitfield tags are packed MSB-first into one shared 64-bit
+			// word, the same way mcproto.Position packs x/y/z, so a run
+			// of consecutive bitfield fields shares a single op rather
+			// than each claiming its own 8 bytes of the wire.
+			if n := len(d.fields); n > 0 && d.fields[n-1].kind == "bitfield" {
+				d.fields[n-1].bits = append(d.fields[n-1].bits, slot)
+				continue
+			}
+			op.bits = []bitSlot{slot}
+		}
+
+		d.fields = append(d.fields, op)
+	}
+	return &d, nil
+}
+
+// SizeOf returns the number of bytes Marshal would write for v, using the
+// same cached type descriptor Marshal and Unmarshal use, so callers can
+// size a buffer without a dry-run marshal.
+func SizeOf(v interface{}) (int, error) {
+	rv := indirect(reflect.ValueOf(v))
+	d, err := descriptorFor(rv.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	var size int
+	for _, op := range d.fields {
+		n, err := sizeField(op, rv)
+		if err != nil {
+			return 0, err
+		}
+		size += n
+	}
+	return size, nil
+}
+
+// Marshal encodes v, a struct or pointer to one with mcproto field tags,
+// into a newly allocated buffer sized with SizeOf.
+func Marshal(v interface{}) ([]byte, error) {
+	size, err := SizeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := MarshalTo(buf, v)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// MarshalTo encodes v's tagged fields into buf and returns the number of
+// bytes written.
+func MarshalTo(buf []byte, v interface{}) (n int, err error) {
+	rv := indirect(reflect.ValueOf(v))
+	d, err := descriptorFor(rv.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, op := range d.fields {
+		m, err := marshalField(op, buf[n:], rv)
+		if n += m; err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Unmarshal decodes buf into v's tagged fields, where v must be a
+// non-nil pointer to a struct, and returns the number of bytes consumed.
+func Unmarshal(buf []byte, v interface{}) (n int, err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return 0, errors.New("packet: Unmarshal requires a non-nil pointer")
+	}
+	rv = rv.Elem()
+
+	d, err := descriptorFor(rv.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, op := range d.fields {
+		m, err := unmarshalField(op, buf[n:], rv)
+		if n += m; err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// sizeScalar, marshalScalar and unmarshalScalar handle the kinds that
+// stand on their own: not "optional", "array" or "bitfield", which wrap
+// one of these (or, for bitfield, pack several integer fields together)
+// and are handled directly in sizeField/marshalField/unmarshalField.
+func sizeScalar(kind string, fv reflect.Value) (int, error) {
+	switch kind {
+	case "varint":
+		return mcproto.LenVarInt(int32(fv.Int())), nil
+	case "varlong":
+		return mcproto.LenVarLong(fv.Int()), nil
+	case "string":
+		s := fv.String()
+		return mcproto.LenVarInt(int32(len(s))) + len(s), nil
+	case "bool", "byte":
+		return 1, nil
+	case "short":
+		return 2, nil
+	case "int", "float":
+		return 4, nil
+	case "long", "double":
+		return 8, nil
+	case "position":
+		return positionLen, nil
+	case "uuid":
+		return 16, nil
+	default:
+		return 0, errors.Errorf("packet: unknown tag kind %q", kind)
+	}
+}
+
+func marshalScalar(kind string, buf []byte, fv reflect.Value) (int, error) {
+	switch kind {
+	case "varint":
+		return mcproto.PutVarInt(buf, int32(fv.Int()))
+	case "varlong":
+		return mcproto.PutVarLong(buf, fv.Int())
+	case "string":
+		return mcproto.PutString(buf, fv.String())
+	case "bool":
+		return PutBool(buf, fv.Bool())
+	case "byte":
+		return PutByte(buf, int8(fv.Int()))
+	case "short":
+		return PutShort(buf, int16(fv.Int()))
+	case "int":
+		return PutInt(buf, int32(fv.Int()))
+	case "long":
+		return PutLong(buf, fv.Int())
+	case "float":
+		return PutFloat(buf, float32(fv.Float()))
+	case "double":
+		return PutDouble(buf, fv.Float())
+	case "position":
+		return mcproto.PutPosition(buf, fv.Interface().(mcproto.Position))
+	case "uuid":
+		return PutUUID(buf, fv.Interface().(UUID))
+	default:
+		return 0, errors.Errorf("packet: unknown tag kind %q", kind)
+	}
+}
+
+func unmarshalScalar(kind string, buf []byte, fv reflect.Value) (int, error) {
+	switch kind {
+	case "varint":
+		v, n, err := mcproto.GetVarInt(buf)
+		fv.SetInt(int64(v))
+		return n, err
+	case "varlong":
+		v, n, err := mcproto.GetVarLong(buf)
+		fv.SetInt(v)
+		return n, err
+	case "string":
+		v, n, err := mcproto.GetString(buf)
+		fv.SetString(v)
+		return n, err
+	case "bool":
+		v, n, err := GetBool(buf)
+		fv.SetBool(v)
+		return n, err
+	case "byte":
+		v, n, err := GetByte(buf)
+		fv.SetInt(int64(v))
+		return n, err
+	case "short":
+		v, n, err := GetShort(buf)
+		fv.SetInt(int64(v))
+		return n, err
+	case "int":
+		v, n, err := GetInt(buf)
+		fv.SetInt(int64(v))
+		return n, err
+	case "long":
+		v, n, err := GetLong(buf)
+		fv.SetInt(v)
+		return n, err
+	case "float":
+		v, n, err := GetFloat(buf)
+		fv.SetFloat(float64(v))
+		return n, err
+	case "double":
+		v, n, err := GetDouble(buf)
+		fv.SetFloat(v)
+		return n, err
+	case "position":
+		v, n, err := mcproto.GetPosition(buf)
+		fv.Set(reflect.ValueOf(v))
+		return n, err
+	case "uuid":
+		v, n, err := GetUUID(buf)
+		fv.Set(reflect.ValueOf(v))
+		return n, err
+	default:
+		return 0, errors.Errorf("packet: unknown tag kind %q", kind)
+	}
+}
+
+func sizeField(op fieldOp, rv reflect.Value) (int, error) {
+	fv := rv.Field(op.index)
+	switch op.kind {
+	case "optional":
+		if !rv.Field(op.present).Bool() {
+			return 1, nil
+		}
+		n, err := sizeScalar(op.elem, fv.Elem())
+		return 1 + n, err
+	case "array":
+		n := mcproto.LenVarInt(int32(fv.Len()))
+		for i := 0; i < fv.Len(); i++ {
+			m, err := sizeScalar(op.elem, fv.Index(i))
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		return n, nil
+	case "bitfield":
+		return 8, nil
+	default:
+		return sizeScalar(op.kind, fv)
+	}
+}
+
+func marshalField(op fieldOp, buf []byte, rv reflect.Value) (int, error) {
+	fv := rv.Field(op.index)
+	switch op.kind {
+	case "optional":
+		present := rv.Field(op.present).Bool()
+		n, err := PutBool(buf, present)
+		if err != nil {
+			return n, err
+		}
+		if !present {
+			return n, nil
+		}
+		m, err := marshalScalar(op.elem, buf[n:], fv.Elem())
+		return n + m, err
+	case "array":
+		n, err := mcproto.PutVarInt(buf, int32(fv.Len()))
+		if err != nil {
+			return n, err
+		}
+		for i := 0; i < fv.Len(); i++ {
+			m, err := marshalScalar(op.elem, buf[n:], fv.Index(i))
+			n += m
+			if err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	case "bitfield":
+		var word uint64
+		for _, slot := range op.bits {
+			word |= mcproto.PutField(rv.Field(slot.index).Int(), slot.size, slot.offset)
+		}
+		return PutLong(buf, int64(word))
+	default:
+		return marshalScalar(op.kind, buf, fv)
+	}
+}
+
+func unmarshalField(op fieldOp, buf []byte, rv reflect.Value) (int, error) {
+	fv := rv.Field(op.index)
+	switch op.kind {
+	case "string":
+		n, err := unmarshalScalar(op.kind, buf, fv)
+		if err == nil && op.max > 0 && int64(fv.Len()) > op.max {
+			return n, errors.Errorf("packet: string exceeds max length %d", op.max)
+		}
+		return n, err
+	case "optional":
+		present, n, err := GetBool(buf)
+		if err != nil {
+			return n, err
+		}
+		rv.Field(op.present).SetBool(present)
+		if !present {
+			return n, nil
+		}
+		ev := reflect.New(fv.Type().Elem())
+		m, err := unmarshalScalar(op.elem, buf[n:], ev.Elem())
+		n += m
+		if err != nil {
+			return n, err
+		}
+		fv.Set(ev)
+		return n, nil
+	case "array":
+		length, n, err := mcproto.GetVarInt(buf)
+		if err != nil {
+			return n, err
+		}
+		// Every element is at least 1 byte on the wire, so bounding
+		// length against the rest of buf is loose, but it's enough to
+		// reject a forged length before MakeSlice allocates a huge
+		// slice (or panics outright on a negative length) from a
+		// handful of input bytes.
+		if length < 0 || int(length) > len(buf)-n {
+			return n, errors.WithStack(errBufTooSmall)
+		}
+		fv.Set(reflect.MakeSlice(fv.Type(), int(length), int(length)))
+		for i := 0; i < int(length); i++ {
+			m, err := unmarshalScalar(op.elem, buf[n:], fv.Index(i))
+			n += m
+			if err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	case "bitfield":
+		v, n, err := GetLong(buf)
+		if err != nil {
+			return n, err
+		}
+		for _, slot := range op.bits {
+			rv.Field(slot.index).SetInt(mcproto.GetField(uint64(v), slot.size, slot.offset))
+		}
+		return n, nil
+	default:
+		return unmarshalScalar(op.kind, buf, fv)
+	}
+}
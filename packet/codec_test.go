@@ -0,0 +1,141 @@
+package packet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njhanley/mcproto"
+)
+
+type testPacket struct {
+	ID       int32            `mcproto:"varint"`
+	Name     string           `mcproto:"string,max=32767"`
+	Pos      mcproto.Position `mcproto:"position"`
+	UUID     UUID             `mcproto:"uuid"`
+	Hidden   bool             `mcproto:"bool"`
+	Tags     []int32          `mcproto:"array,len=varint,elem=varint"`
+	HasExtra bool
+	Extra    *string `mcproto:"optional,present=HasExtra,elem=string"`
+}
+
+// bitfieldPacket mirrors how mcproto.Position itself packs x/y/z into one
+// 64-bit word, to exercise a multi-field "bitfield" group.
+type bitfieldPacket struct {
+	X int32 `mcproto:"bitfield,size=26,offset=38"`
+	Y int16 `mcproto:"bitfield,size=12,offset=26"`
+	Z int32 `mcproto:"bitfield,size=26,offset=0"`
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	extra := "extra data"
+	p := testPacket{
+		ID:       300,
+		Name:     "hello",
+		UUID:     UUID{1, 2, 3},
+		Hidden:   true,
+		Tags:     []int32{1, 2, 300},
+		HasExtra: true,
+		Extra:    &extra,
+	}
+
+	data, err := Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %#v", err)
+	}
+
+	size, err := SizeOf(&p)
+	if err != nil {
+		t.Fatalf("SizeOf failed: %#v", err)
+	}
+	if size != len(data) {
+		t.Errorf("SizeOf: have %d, got %d", len(data), size)
+	}
+
+	var got testPacket
+	n, err := Unmarshal(data, &got)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %#v", err)
+	}
+	if n != len(data) {
+		t.Errorf("consumed %d bytes, wrote %d", n, len(data))
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("have: %+v, got: %+v", p, got)
+	}
+}
+
+func TestMarshalUnmarshalOptionalAbsent(t *testing.T) {
+	p := testPacket{ID: 1, Name: "x", UUID: UUID{}, HasExtra: false}
+
+	data, err := Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %#v", err)
+	}
+
+	var got testPacket
+	if _, err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %#v", err)
+	}
+	if got.Extra != nil {
+		t.Errorf("expected Extra to be nil, got %v", *got.Extra)
+	}
+}
+
+func TestMarshalUnmarshalBitfield(t *testing.T) {
+	p := bitfieldPacket{X: 123, Y: -45, Z: 678}
+
+	data, err := Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %#v", err)
+	}
+	if len(data) != 8 {
+		t.Fatalf("expected one 8-byte word, got %d bytes", len(data))
+	}
+
+	var got bitfieldPacket
+	if _, err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %#v", err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("have: %+v, got: %+v", p, got)
+	}
+}
+
+func TestUnmarshalArrayForgedLength(t *testing.T) {
+	type arrayPacket struct {
+		Tags []int32 `mcproto:"array,len=varint,elem=varint"`
+	}
+
+	cases := []struct {
+		name   string
+		length int32
+	}{
+		{"negative", -1},
+		{"larger than buf could hold", 0x7fffffff},
+	}
+
+	for _, c := range cases {
+		buf := mcproto.AppendVarInt(nil, c.length)
+		var got arrayPacket
+		if _, err := Unmarshal(buf, &got); err == nil {
+			t.Errorf("%s: expected an error for a length the buffer can't hold, got nil", c.name)
+		}
+	}
+}
+
+func TestDescriptorCached(t *testing.T) {
+	t1 := reflect.TypeOf(testPacket{})
+	if _, err := descriptorFor(t1); err != nil {
+		t.Fatalf("descriptorFor failed: %#v", err)
+	}
+	d1, _ := descriptorCache.Load(t1)
+
+	if _, err := descriptorFor(t1); err != nil {
+		t.Fatalf("descriptorFor failed: %#v", err)
+	}
+	d2, _ := descriptorCache.Load(t1)
+
+	if d1 != d2 {
+		t.Errorf("expected the same cached descriptor, got a new one")
+	}
+}
@@ -0,0 +1,54 @@
+package packet
+
+import "github.com/njhanley/mcproto"
+
+// Conn wraps an *mcproto.Conn with a Registry and the connection's
+// current State, so ReadPacket/WritePacket can work directly in typed
+// Packet values instead of raw mcproto.Packet frames. dir is the
+// direction of packets this Conn reads, used for the Registry lookup on
+// each ReadPacket; WritePacket needs no direction, since a typed
+// packet's own ID is enough to encode it.
+//
+// A Conn is not safe for concurrent use.
+type Conn struct {
+	*mcproto.Conn
+	registry *Registry
+	dir      Direction
+	state    State
+}
+
+// NewConn returns a Conn reading dir-direction packets over conn,
+// decoding and encoding them with registry. It starts in the Handshaking
+// state, as every Minecraft connection does.
+func NewConn(conn *mcproto.Conn, registry *Registry, dir Direction) *Conn {
+	return &Conn{Conn: conn, registry: registry, dir: dir}
+}
+
+// SetState switches the connection to state, changing which packet types
+// ReadPacket recognizes from this point on. It must be
+// called at the same point in the stream the real client/server would
+// switch states, typically right after reading or writing the packet
+// that triggers the transition (e.g. Login Success moving Login to
+// Configuration).
+func (c *Conn) SetState(state State) {
+	c.state = state
+}
+
+// ReadPacket reads the next raw packet from the connection and decodes
+// it into the concrete type registered for it in the current state.
+func (c *Conn) ReadPacket() (Packet, error) {
+	raw, err := c.Conn.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+	return c.registry.Decode(c.state, c.dir, raw)
+}
+
+// WritePacket encodes p and writes it to the connection.
+func (c *Conn) WritePacket(p Packet) error {
+	raw, err := Encode(p)
+	if err != nil {
+		return err
+	}
+	return c.Conn.WritePacket(raw)
+}
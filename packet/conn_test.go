@@ -0,0 +1,79 @@
+package packet
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/njhanley/mcproto"
+)
+
+func TestConnReadWritePacket(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	registry := NewRegistry()
+	registry.Register(Handshaking, Serverbound, 0x01, func() Packet { return &fakePacket{} })
+	registry.Register(Play, Serverbound, 0x01, func() Packet { return &fakePacket{} })
+
+	client := NewConn(mcproto.NewConn(clientConn), registry, Clientbound)
+	server := NewConn(mcproto.NewConn(serverConn), registry, Serverbound)
+
+	// Handshaking state: fakePacket is registered at id 0x01 here and in
+	// Play, and its ID() is 0x01 regardless of state. Both registrations
+	// use the same constructor, so this does not tell the two states
+	// apart; TestConnReadPacketUnregisteredState covers a state with no
+	// registration.
+	sent := &fakePacket{value: 0x2a}
+	errCh := make(chan error, 1)
+	go func() { errCh <- client.WritePacket(sent) }()
+
+	got, err := server.ReadPacket()
+	if err != nil {
+		t.Fatalf("ReadPacket failed: %#v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("WritePacket failed: %#v", err)
+	}
+	if !reflect.DeepEqual(got, Packet(sent)) {
+		t.Errorf("have: %#v, got: %#v", sent, got)
+	}
+
+	client.SetState(Play)
+	server.SetState(Play)
+
+	sent = &fakePacket{value: 0x7f}
+	go func() { errCh <- client.WritePacket(sent) }()
+
+	got, err = server.ReadPacket()
+	if err != nil {
+		t.Fatalf("ReadPacket after SetState failed: %#v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("WritePacket after SetState failed: %#v", err)
+	}
+	if !reflect.DeepEqual(got, Packet(sent)) {
+		t.Errorf("have: %#v, got: %#v", sent, got)
+	}
+}
+
+func TestConnReadPacketUnregisteredState(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	registry := NewRegistry()
+	registry.Register(Play, Serverbound, 0x01, func() Packet { return &fakePacket{} })
+
+	client := NewConn(mcproto.NewConn(clientConn), registry, Clientbound)
+	server := NewConn(mcproto.NewConn(serverConn), registry, Serverbound)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- client.WritePacket(&fakePacket{value: 1}) }()
+
+	if _, err := server.ReadPacket(); err == nil {
+		t.Errorf("expected an error decoding a packet not registered in the Handshaking state")
+	}
+	<-errCh
+}
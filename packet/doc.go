@@ -0,0 +1,40 @@
+// Package packet provides a typed, schema-driven alternative to handling
+// raw mcproto.Packet values by hand. Users declare Go structs describing
+// a packet's on-wire layout with field tags:
+//
+//	type Handshake struct {
+//		ProtocolVersion int32  `mcproto:"varint"`
+//		ServerAddress   string `mcproto:"string,max=255"`
+//		ServerPort      int16  `mcproto:"short"`
+//		NextState       int32  `mcproto:"varint"`
+//	}
+//
+// Recognized tags are "varint", "varlong", "bool", "byte", "short", "int",
+// "long", "float", "double", "position", "uuid" and "string[,max=N]".
+//
+// Running `go generate` over a file containing such structs, annotated
+// with a "mcproto:packet" directive, invokes the mcprotogen tool (see the
+// mcprotogen subpackage) to emit zero-reflection Marshal/Unmarshal methods
+// satisfying the Packet interface below.
+//
+// The package-level Marshal, Unmarshal and SizeOf functions offer a
+// reflection-based alternative over the same scalar tags, plus three
+// mcprotogen does not yet generate code for:
+//
+//   - "optional,present=Field,elem=kind" for a pointer field that is only
+//     (un)marshaled, as the named kind, when the named sibling bool field
+//     is true.
+//   - "array,len=varint,elem=kind" for a slice field, (un)marshaled as a
+//     VarInt length followed by that many values of the named kind.
+//   - "bitfield,size=N,offset=M" for an integer field packed into a
+//     shared 64-bit word via mcproto.PutField/GetField, the same way
+//     mcproto.Position itself packs x/y/z; a run of consecutive
+//     "bitfield" fields shares one word.
+//
+// Each reflect.Type's tags are walked once into a cached type descriptor,
+// so repeated calls for the same struct type only pay for reflect.Value
+// field access, not the tag parse.
+//
+// A Registry maps (State, Direction, ID) triples to packet constructors,
+// so a proxy can decode an mcproto.Packet into a typed value in one call.
+package packet
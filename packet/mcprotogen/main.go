@@ -0,0 +1,285 @@
+// Command mcprotogen generates zero-reflection Marshal/Unmarshal methods
+// for packet structs declared in a Go source file, driven by `mcproto:"…"`
+// field tags and a `mcproto:packet id=… state=… dir=…` directive on the
+// struct's doc comment. It is meant to be invoked via:
+//
+//	//go:generate mcprotogen $GOFILE
+//
+// See the packet package's doc comment for the supported tag grammar.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// packetSpec is the parsed `mcproto:packet` directive for a struct.
+type packetSpec struct {
+	id    int64
+	state string
+	dir   string
+}
+
+// fieldSpec is a field's parsed `mcproto:"…"` tag.
+type fieldSpec struct {
+	name    string
+	kind    string
+	max     int64 // string
+	present string // optional
+}
+
+type structInfo struct {
+	name   string
+	spec   packetSpec
+	fields []fieldSpec
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: mcprotogen <file.go>")
+	}
+	if err := run(os.Args[1]); err != nil {
+		log.Fatalf("mcprotogen: %v", err)
+	}
+}
+
+func run(path string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	var structs []structInfo
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			doc := ts.Doc
+			if doc == nil {
+				doc = gd.Doc
+			}
+			pspec, ok, err := parsePacketDirective(doc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", ts.Name.Name, err)
+			}
+			if !ok {
+				continue
+			}
+
+			fields, err := parseFields(st)
+			if err != nil {
+				return fmt.Errorf("%s: %w", ts.Name.Name, err)
+			}
+
+			structs = append(structs, structInfo{name: ts.Name.Name, spec: pspec, fields: fields})
+		}
+	}
+
+	if len(structs) == 0 {
+		return nil // nothing to generate; not an error so go:generate stays quiet
+	}
+
+	var needsMcproto, needsPacket bool
+	for _, s := range structs {
+		for _, fs := range s.fields {
+			c := codec[fs.kind]
+			if strings.HasPrefix(c.get, "mcproto.") || fs.kind == "optional" {
+				needsMcproto = true
+			}
+			if strings.HasPrefix(c.get, "packet.") || fs.kind == "optional" {
+				needsPacket = true
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by mcprotogen from %s. DO NOT EDIT.\n\n", filepath.Base(path))
+	fmt.Fprintf(&buf, "package %s\n\n", f.Name.Name)
+	if needsMcproto || needsPacket {
+		buf.WriteString("import (\n")
+		if needsMcproto {
+			buf.WriteString("\t\"github.com/njhanley/mcproto\"\n")
+		}
+		if needsPacket {
+			buf.WriteString("\t\"github.com/njhanley/mcproto/packet\"\n")
+		}
+		buf.WriteString(")\n")
+	}
+
+	for _, s := range structs {
+		writeStruct(&buf, s)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated source: %w", err)
+	}
+
+	outPath := strings.TrimSuffix(path, ".go") + "_mcproto.go"
+	return os.WriteFile(outPath, out, 0o644)
+}
+
+// parsePacketDirective looks for a "mcproto:packet ..." line in a doc
+// comment and parses its id/state/dir fields.
+func parsePacketDirective(doc *ast.CommentGroup) (packetSpec, bool, error) {
+	if doc == nil {
+		return packetSpec{}, false, nil
+	}
+	for _, c := range doc.List {
+		text := strings.TrimPrefix(strings.TrimPrefix(c.Text, "//"), " ")
+		if !strings.HasPrefix(text, "mcproto:packet ") {
+			continue
+		}
+
+		var spec packetSpec
+		for _, field := range strings.Fields(strings.TrimPrefix(text, "mcproto:packet ")) {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return packetSpec{}, false, fmt.Errorf("malformed mcproto:packet directive field %q", field)
+			}
+			switch kv[0] {
+			case "id":
+				id, err := strconv.ParseInt(kv[1], 0, 32)
+				if err != nil {
+					return packetSpec{}, false, fmt.Errorf("invalid id %q: %w", kv[1], err)
+				}
+				spec.id = id
+			case "state":
+				spec.state = kv[1]
+			case "dir":
+				spec.dir = kv[1]
+			default:
+				return packetSpec{}, false, fmt.Errorf("unknown mcproto:packet field %q", kv[0])
+			}
+		}
+		return spec, true, nil
+	}
+	return packetSpec{}, false, nil
+}
+
+func parseFields(st *ast.StructType) ([]fieldSpec, error) {
+	var fields []fieldSpec
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+		tagValue, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %s: %w", f.Tag.Value, err)
+		}
+		tag := reflect.StructTag(tagValue).Get("mcproto")
+		if tag == "" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		fs := fieldSpec{name: f.Names[0].Name, kind: parts[0]}
+		for _, opt := range parts[1:] {
+			kv := strings.SplitN(opt, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("%s: malformed tag option %q", fs.name, opt)
+			}
+			switch kv[0] {
+			case "max":
+				n, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid max %q: %w", fs.name, kv[1], err)
+				}
+				fs.max = n
+			case "present":
+				fs.present = kv[1]
+			default:
+				return nil, fmt.Errorf("%s: unknown tag option %q", fs.name, kv[0])
+			}
+		}
+		if fs.kind == "optional" && fs.present == "" {
+			return nil, fmt.Errorf("%s: optional field requires present=Field", fs.name)
+		}
+
+		fields = append(fields, fs)
+	}
+	return fields, nil
+}
+
+// codec names the Get.../Put... function pair used to (de)serialize a
+// scalar kind, already qualified for use from a generated file that
+// imports the mcproto and packet packages.
+var codec = map[string]struct{ get, put string }{
+	"varint":   {"mcproto.GetVarInt", "mcproto.PutVarInt"},
+	"varlong":  {"mcproto.GetVarLong", "mcproto.PutVarLong"},
+	"string":   {"mcproto.GetString", "mcproto.PutString"},
+	"position": {"mcproto.GetPosition", "mcproto.PutPosition"},
+	"bool":     {"packet.GetBool", "packet.PutBool"},
+	"byte":     {"packet.GetByte", "packet.PutByte"},
+	"short":    {"packet.GetShort", "packet.PutShort"},
+	"int":      {"packet.GetInt", "packet.PutInt"},
+	"long":     {"packet.GetLong", "packet.PutLong"},
+	"float":    {"packet.GetFloat", "packet.PutFloat"},
+	"double":   {"packet.GetDouble", "packet.PutDouble"},
+	"uuid":     {"packet.GetUUID", "packet.PutUUID"},
+}
+
+func writeStruct(buf *bytes.Buffer, s structInfo) {
+	fmt.Fprintf(buf, "func (p *%s) ID() int32 { return %#x }\n\n", s.name, s.spec.id)
+
+	fmt.Fprintf(buf, "func (p *%s) Marshal(buf []byte) (int, error) {\n\tvar n int\n", s.name)
+	for _, fs := range s.fields {
+		writeMarshalField(buf, fs)
+	}
+	buf.WriteString("\treturn n, nil\n}\n\n")
+
+	fmt.Fprintf(buf, "func (p *%s) Unmarshal(buf []byte) (int, error) {\n\tvar n int\n", s.name)
+	for _, fs := range s.fields {
+		writeUnmarshalField(buf, fs)
+	}
+	buf.WriteString("\treturn n, nil\n}\n\n")
+}
+
+func writeMarshalField(buf *bytes.Buffer, fs fieldSpec) {
+	if fs.kind == "optional" {
+		fmt.Fprintf(buf, "\tif _, err := packet.PutBool(buf[n:], p.%s); err != nil {\n\t\treturn n, err\n\t}\n\tn++\n", fs.present)
+		fmt.Fprintf(buf, "\tif p.%s {\n", fs.present)
+		fmt.Fprintf(buf, "\t\tm, err := mcproto.PutString(buf[n:], *p.%s)\n\t\tif n += m; err != nil {\n\t\t\treturn n, err\n\t\t}\n", fs.name)
+		buf.WriteString("\t}\n")
+		return
+	}
+
+	c := codec[fs.kind]
+	fmt.Fprintf(buf, "\t{\n\t\tm, err := %s(buf[n:], p.%s)\n\t\tif n += m; err != nil {\n\t\t\treturn n, err\n\t\t}\n\t}\n", c.put, fs.name)
+}
+
+func writeUnmarshalField(buf *bytes.Buffer, fs fieldSpec) {
+	if fs.kind == "optional" {
+		fmt.Fprintf(buf, "\t{\n\t\tv, m, err := packet.GetBool(buf[n:])\n\t\tif n += m; err != nil {\n\t\t\treturn n, err\n\t\t}\n\t\tp.%s = v\n\t}\n", fs.present)
+		fmt.Fprintf(buf, "\tif p.%s {\n", fs.present)
+		fmt.Fprintf(buf, "\t\tv, m, err := mcproto.GetString(buf[n:])\n\t\tif n += m; err != nil {\n\t\t\treturn n, err\n\t\t}\n\t\tp.%s = &v\n", fs.name)
+		buf.WriteString("\t}\n")
+		return
+	}
+
+	c := codec[fs.kind]
+	fmt.Fprintf(buf, "\t{\n\t\tv, m, err := %s(buf[n:])\n\t\tif n += m; err != nil {\n\t\t\treturn n, err\n\t\t}\n", c.get)
+	fmt.Fprintf(buf, "\t\tp.%s = v\n\t}\n", fs.name)
+}
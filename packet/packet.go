@@ -0,0 +1,14 @@
+package packet
+
+// Packet is implemented by generated (or hand-written) typed packet
+// structs so they can be decoded and encoded through a Registry.
+type Packet interface {
+	// ID is the packet's protocol id within its State and Direction.
+	ID() int32
+	// Marshal encodes the packet into buf, which must be large enough to
+	// hold the encoded packet, and returns the number of bytes written.
+	Marshal(buf []byte) (int, error)
+	// Unmarshal decodes the packet from buf and returns the number of
+	// bytes consumed.
+	Unmarshal(buf []byte) (int, error)
+}
@@ -0,0 +1,130 @@
+package packet
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+var errBufTooSmall = errors.New("buf too small")
+
+// UUID is a 128-bit Minecraft UUID, encoded on the wire as 16 big-endian
+// bytes.
+type UUID [16]byte
+
+// Fixed-size primitives that the varint/string/position helpers in the
+// mcproto package don't cover, used by generated packet codecs.
+
+func GetBool(buf []byte) (v bool, n int, err error) {
+	if len(buf) < 1 {
+		return false, 0, errors.WithStack(errBufTooSmall)
+	}
+	return buf[0] != 0, 1, nil
+}
+
+func PutBool(buf []byte, v bool) (n int, err error) {
+	if len(buf) < 1 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	if v {
+		buf[0] = 1
+	} else {
+		buf[0] = 0
+	}
+	return 1, nil
+}
+
+func GetByte(buf []byte) (v int8, n int, err error) {
+	if len(buf) < 1 {
+		return 0, 0, errors.WithStack(errBufTooSmall)
+	}
+	return int8(buf[0]), 1, nil
+}
+
+func PutByte(buf []byte, v int8) (n int, err error) {
+	if len(buf) < 1 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	buf[0] = byte(v)
+	return 1, nil
+}
+
+func GetShort(buf []byte) (v int16, n int, err error) {
+	if len(buf) < 2 {
+		return 0, 0, errors.WithStack(errBufTooSmall)
+	}
+	return int16(binary.BigEndian.Uint16(buf)), 2, nil
+}
+
+func PutShort(buf []byte, v int16) (n int, err error) {
+	if len(buf) < 2 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint16(buf, uint16(v))
+	return 2, nil
+}
+
+func GetInt(buf []byte) (v int32, n int, err error) {
+	if len(buf) < 4 {
+		return 0, 0, errors.WithStack(errBufTooSmall)
+	}
+	return int32(binary.BigEndian.Uint32(buf)), 4, nil
+}
+
+func PutInt(buf []byte, v int32) (n int, err error) {
+	if len(buf) < 4 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(v))
+	return 4, nil
+}
+
+func GetLong(buf []byte) (v int64, n int, err error) {
+	if len(buf) < 8 {
+		return 0, 0, errors.WithStack(errBufTooSmall)
+	}
+	return int64(binary.BigEndian.Uint64(buf)), 8, nil
+}
+
+func PutLong(buf []byte, v int64) (n int, err error) {
+	if len(buf) < 8 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	binary.BigEndian.PutUint64(buf, uint64(v))
+	return 8, nil
+}
+
+func GetFloat(buf []byte) (v float32, n int, err error) {
+	i, n, err := GetInt(buf)
+	return math.Float32frombits(uint32(i)), n, err
+}
+
+func PutFloat(buf []byte, v float32) (n int, err error) {
+	return PutInt(buf, int32(math.Float32bits(v)))
+}
+
+func GetDouble(buf []byte) (v float64, n int, err error) {
+	i, n, err := GetLong(buf)
+	return math.Float64frombits(uint64(i)), n, err
+}
+
+func PutDouble(buf []byte, v float64) (n int, err error) {
+	return PutLong(buf, int64(math.Float64bits(v)))
+}
+
+func GetUUID(buf []byte) (u UUID, n int, err error) {
+	if len(buf) < 16 {
+		return u, 0, errors.WithStack(errBufTooSmall)
+	}
+	copy(u[:], buf[:16])
+	return u, 16, nil
+}
+
+func PutUUID(buf []byte, u UUID) (n int, err error) {
+	if len(buf) < 16 {
+		return 0, errors.WithStack(errBufTooSmall)
+	}
+	copy(buf, u[:])
+	return 16, nil
+}
@@ -0,0 +1,67 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetPutBool(t *testing.T) {
+	for _, v := range []bool{true, false} {
+		buf := make([]byte, 1)
+		if _, err := PutBool(buf, v); err != nil {
+			t.Fatalf("PutBool(%v) failed: %#v", v, err)
+		}
+		got, n, err := GetBool(buf)
+		if got != v || n != 1 || err != nil {
+			t.Errorf("GetBool(%#v) have: %#v, got: (%#v, %#v, %#v)", buf, v, got, n, err)
+		}
+	}
+}
+
+func TestGetPutShort(t *testing.T) {
+	buf := make([]byte, 2)
+	if _, err := PutShort(buf, -1234); err != nil {
+		t.Fatalf("PutShort failed: %#v", err)
+	}
+	v, n, err := GetShort(buf)
+	if v != -1234 || n != 2 || err != nil {
+		t.Errorf("have: -1234, got: (%#v, %#v, %#v)", v, n, err)
+	}
+}
+
+func TestGetPutLong(t *testing.T) {
+	buf := make([]byte, 8)
+	if _, err := PutLong(buf, 1<<40); err != nil {
+		t.Fatalf("PutLong failed: %#v", err)
+	}
+	v, n, err := GetLong(buf)
+	if v != 1<<40 || n != 8 || err != nil {
+		t.Errorf("have: %#v, got: (%#v, %#v, %#v)", int64(1<<40), v, n, err)
+	}
+}
+
+func TestGetPutDouble(t *testing.T) {
+	buf := make([]byte, 8)
+	if _, err := PutDouble(buf, 3.14159); err != nil {
+		t.Fatalf("PutDouble failed: %#v", err)
+	}
+	v, n, err := GetDouble(buf)
+	if v != 3.14159 || n != 8 || err != nil {
+		t.Errorf("have: 3.14159, got: (%#v, %#v, %#v)", v, n, err)
+	}
+}
+
+func TestGetPutUUID(t *testing.T) {
+	u := UUID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	buf := make([]byte, 16)
+	if _, err := PutUUID(buf, u); err != nil {
+		t.Fatalf("PutUUID failed: %#v", err)
+	}
+	if !bytes.Equal(buf, u[:]) {
+		t.Errorf("have: %#v, got: %#v", u[:], buf)
+	}
+	got, n, err := GetUUID(buf)
+	if got != u || n != 16 || err != nil {
+		t.Errorf("have: %#v, got: (%#v, %#v, %#v)", u, got, n, err)
+	}
+}
@@ -0,0 +1,94 @@
+package packet
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/njhanley/mcproto"
+)
+
+// New constructs a zero-valued Packet of a concrete type registered with
+// a Registry.
+type New func() Packet
+
+type key struct {
+	state State
+	dir   Direction
+	id    int32
+}
+
+// Registry maps (State, Direction, ID) triples to packet constructors, so
+// a raw mcproto.Packet can be decoded into the concrete Go type registered
+// for its state, direction and id.
+//
+// A Registry forked from another with Fork looks up anything it has no
+// entry of its own for in its parent, so callers can layer modded or
+// version-specific packets on top of a shared built-in registry without
+// mutating it or copying its entries up front.
+type Registry struct {
+	parent  *Registry
+	entries map[key]New
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Fork returns a new Registry that consults r for any (state, dir, id)
+// it has no entry of its own for. Registering a packet type on the fork
+// never modifies r, so the same base registry can be forked many times
+// over, e.g. once per connection that wants to add its own packets.
+func (r *Registry) Fork() *Registry {
+	return &Registry{parent: r}
+}
+
+// Register associates id within state and dir with new, the constructor
+// for the concrete Packet type that handles it.
+func (r *Registry) Register(state State, dir Direction, id int32, new New) {
+	if r.entries == nil {
+		r.entries = make(map[key]New)
+	}
+	r.entries[key{state, dir, id}] = new
+}
+
+// Lookup returns the constructor registered for id within state and dir,
+// if any, checking r's parent (if it has one) when r has no entry of its
+// own.
+func (r *Registry) Lookup(state State, dir Direction, id int32) (New, bool) {
+	if new, ok := r.entries[key{state, dir, id}]; ok {
+		return new, true
+	}
+	if r.parent != nil {
+		return r.parent.Lookup(state, dir, id)
+	}
+	return nil, false
+}
+
+// Decode constructs the packet type registered for raw's id within state
+// and dir, and unmarshals raw's data into it.
+func (r *Registry) Decode(state State, dir Direction, raw mcproto.Packet) (Packet, error) {
+	new, ok := r.Lookup(state, dir, raw.ID)
+	if !ok {
+		return nil, errors.Errorf("packet: no packet registered for %s %s id 0x%02x", state, dir, raw.ID)
+	}
+
+	p := new()
+	if _, err := p.Unmarshal(raw.Data); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Encode marshals p into an mcproto.Packet using p's own ID.
+func Encode(p Packet) (mcproto.Packet, error) {
+	buf := make([]byte, maxPacketSize)
+	n, err := p.Marshal(buf)
+	if err != nil {
+		return mcproto.Packet{}, err
+	}
+	return mcproto.Packet{ID: p.ID(), Data: buf[:n]}, nil
+}
+
+// maxPacketSize bounds the scratch buffer Encode allocates for a typed
+// packet's marshaled data.
+const maxPacketSize = 1 << 19 // 512 KiB
@@ -0,0 +1,75 @@
+package packet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njhanley/mcproto"
+)
+
+type fakePacket struct {
+	value int32
+}
+
+func (p *fakePacket) ID() int32 { return 0x01 }
+
+func (p *fakePacket) Marshal(buf []byte) (int, error) {
+	return mcproto.PutVarInt(buf, p.value)
+}
+
+func (p *fakePacket) Unmarshal(buf []byte) (int, error) {
+	v, n, err := mcproto.GetVarInt(buf)
+	p.value = v
+	return n, err
+}
+
+func TestRegistryDecode(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Play, Serverbound, 0x01, func() Packet { return &fakePacket{} })
+
+	raw := mcproto.Packet{ID: 0x01, Data: []byte{0x2a}}
+	p, err := r.Decode(Play, Serverbound, raw)
+	if err != nil {
+		t.Fatalf("Decode failed: %#v", err)
+	}
+	if got := p.(*fakePacket); got.value != 0x2a {
+		t.Errorf("have: 0x2a, got: %#v", got.value)
+	}
+}
+
+func TestRegistryDecodeUnregistered(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Decode(Play, Serverbound, mcproto.Packet{ID: 0x01}); err == nil {
+		t.Errorf("expected an error for an unregistered packet id")
+	}
+}
+
+func TestRegistryFork(t *testing.T) {
+	base := NewRegistry()
+	base.Register(Play, Serverbound, 0x01, func() Packet { return &fakePacket{} })
+
+	fork := base.Fork()
+	fork.Register(Play, Serverbound, 0x02, func() Packet { return &fakePacket{} })
+
+	if _, ok := fork.Lookup(Play, Serverbound, 0x01); !ok {
+		t.Errorf("fork did not inherit base's registration")
+	}
+	if _, ok := base.Lookup(Play, Serverbound, 0x02); ok {
+		t.Errorf("registering on fork mutated base")
+	}
+	if _, ok := fork.Lookup(Play, Serverbound, 0x02); !ok {
+		t.Errorf("fork's own registration was not found")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	p := &fakePacket{value: 0x2a}
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatalf("Encode failed: %#v", err)
+	}
+	want := mcproto.Packet{ID: 0x01, Data: []byte{0x2a}}
+	if !reflect.DeepEqual(raw, want) {
+		t.Errorf("have: %#v, got: %#v", want, raw)
+	}
+}
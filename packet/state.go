@@ -0,0 +1,49 @@
+package packet
+
+// State identifies one of the connection states the Minecraft protocol
+// moves through over the lifetime of a connection.
+type State int
+
+const (
+	Handshaking State = iota
+	Status
+	Login
+	Configuration
+	Play
+)
+
+func (s State) String() string {
+	switch s {
+	case Handshaking:
+		return "Handshaking"
+	case Status:
+		return "Status"
+	case Login:
+		return "Login"
+	case Configuration:
+		return "Configuration"
+	case Play:
+		return "Play"
+	default:
+		return "State(?)"
+	}
+}
+
+// Direction identifies which side of a connection a packet travels from.
+type Direction int
+
+const (
+	Serverbound Direction = iota
+	Clientbound
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Serverbound:
+		return "Serverbound"
+	case Clientbound:
+		return "Clientbound"
+	default:
+		return "Direction(?)"
+	}
+}
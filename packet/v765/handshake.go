@@ -0,0 +1,14 @@
+// Package v765 pre-registers the well-known packets of protocol version
+// 765 (Minecraft 1.20.3-1.20.4), starting with the handshake and status
+// states, so callers can decode those without writing any codecs by hand.
+package v765
+
+//go:generate mcprotogen $GOFILE
+
+// mcproto:packet id=0x00 state=Handshaking dir=Serverbound
+type Handshake struct {
+	ProtocolVersion int32  `mcproto:"varint"`
+	ServerAddress   string `mcproto:"string,max=255"`
+	ServerPort      int16  `mcproto:"short"`
+	NextState       int32  `mcproto:"varint"`
+}
@@ -0,0 +1,44 @@
+package v765
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njhanley/mcproto/packet"
+)
+
+func TestHandshakeRoundTrip(t *testing.T) {
+	h := &Handshake{
+		ProtocolVersion: 765,
+		ServerAddress:   "localhost",
+		ServerPort:      25565,
+		NextState:       int32(packet.Status),
+	}
+
+	buf := make([]byte, 64)
+	n, err := h.Marshal(buf)
+	if err != nil {
+		t.Fatalf("Marshal failed: %#v", err)
+	}
+
+	got := &Handshake{}
+	if _, err := got.Unmarshal(buf[:n]); err != nil {
+		t.Fatalf("Unmarshal failed: %#v", err)
+	}
+
+	if !reflect.DeepEqual(got, h) {
+		t.Errorf("have: %#v, got: %#v", h, got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := packet.NewRegistry()
+	Register(r)
+
+	if _, ok := r.Lookup(packet.Handshaking, packet.Serverbound, 0x00); !ok {
+		t.Errorf("Handshake was not registered")
+	}
+	if _, ok := r.Lookup(packet.Status, packet.Clientbound, 0x01); !ok {
+		t.Errorf("PongResponse was not registered")
+	}
+}
@@ -0,0 +1,12 @@
+package v765
+
+import "github.com/njhanley/mcproto/packet"
+
+// Register adds every packet known to this package into r.
+func Register(r *packet.Registry) {
+	r.Register(packet.Handshaking, packet.Serverbound, (&Handshake{}).ID(), func() packet.Packet { return &Handshake{} })
+
+	r.Register(packet.Status, packet.Serverbound, (&StatusRequest{}).ID(), func() packet.Packet { return &StatusRequest{} })
+	r.Register(packet.Status, packet.Serverbound, (&PingRequest{}).ID(), func() packet.Packet { return &PingRequest{} })
+	r.Register(packet.Status, packet.Clientbound, (&PongResponse{}).ID(), func() packet.Packet { return &PongResponse{} })
+}
@@ -0,0 +1,16 @@
+package v765
+
+//go:generate mcprotogen $GOFILE
+
+// mcproto:packet id=0x00 state=Status dir=Serverbound
+type StatusRequest struct{}
+
+// mcproto:packet id=0x01 state=Status dir=Serverbound
+type PingRequest struct {
+	Payload int64 `mcproto:"long"`
+}
+
+// mcproto:packet id=0x01 state=Status dir=Clientbound
+type PongResponse struct {
+	Payload int64 `mcproto:"long"`
+}
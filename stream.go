@@ -0,0 +1,181 @@
+package mcproto
+
+import (
+	"bufio"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// ReadVarInt reads a VarInt from r one byte at a time, so the caller does
+// not need to know its length or buffer the underlying stream in advance.
+func ReadVarInt(r io.ByteReader) (v int32, n int, err error) {
+	_v, n, err := readVarN(r, maxIntBytes)
+	return int32(_v), n, err
+}
+
+// ReadVarLong reads a VarLong from r one byte at a time.
+func ReadVarLong(r io.ByteReader) (v int64, n int, err error) {
+	_v, n, err := readVarN(r, maxLongBytes)
+	return int64(_v), n, err
+}
+
+func readVarN(r io.ByteReader, maxBytes int) (v uint64, n int, err error) {
+	for n = 0; n < maxBytes; n++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, n, err
+		}
+		v |= uint64(b&cmask) << uint(n*cbits)
+		if b&msb == 0 {
+			return v, n + 1, nil
+		}
+	}
+	return 0, maxBytes, errors.WithStack(errValueTooLarge)
+}
+
+// WriteVarInt writes a VarInt to w one byte at a time.
+func WriteVarInt(w io.ByteWriter, v int32) (int, error) {
+	return writeVarN(w, uint64(uint32(v)), maxIntBytes)
+}
+
+// WriteVarLong writes a VarLong to w one byte at a time.
+func WriteVarLong(w io.ByteWriter, v int64) (int, error) {
+	return writeVarN(w, uint64(v), maxLongBytes)
+}
+
+func writeVarN(w io.ByteWriter, v uint64, maxBytes int) (n int, err error) {
+	for n = 0; n < maxBytes; n++ {
+		if v&^cmask == 0 {
+			if err := w.WriteByte(byte(v)); err != nil {
+				return n, err
+			}
+			return n + 1, nil
+		}
+		if err := w.WriteByte(byte(v | msb)); err != nil {
+			return n, err
+		}
+		v >>= cbits
+	}
+	return maxBytes, errors.WithStack(errValueTooLarge)
+}
+
+// PacketReader reads length-prefixed packets from an underlying io.Reader.
+// It is not safe for concurrent use.
+type PacketReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewPacketReader returns a PacketReader that reads packets from r.
+func NewPacketReader(r io.Reader) *PacketReader {
+	return &PacketReader{r: bufio.NewReader(r)}
+}
+
+// ReadPacket reads and returns the next packet from the stream. Each call
+// allocates a new Data slice; callers that read many packets should prefer
+// ReadPacketBuf to reuse a scratch buffer instead.
+func (pr *PacketReader) ReadPacket() (Packet, error) {
+	p, buf, err := pr.ReadPacketBuf(pr.buf)
+	pr.buf = buf
+	if err != nil {
+		return Packet{}, err
+	}
+	data := make([]byte, len(p.Data))
+	copy(data, p.Data)
+	return Packet{ID: p.ID, Data: data}, nil
+}
+
+// ReadPacketBuf reads the next packet using buf as scratch space for its
+// Data, growing buf if it is too small. It returns the packet, along with
+// the buffer backing it so the caller can pass it to the next call and
+// avoid allocating once buf has grown large enough for the connection's
+// packets. The returned Packet's Data is only valid until the next call.
+func (pr *PacketReader) ReadPacketBuf(buf []byte) (Packet, []byte, error) {
+	length, _, err := ReadVarInt(pr.r)
+	if err != nil {
+		return Packet{}, buf, err
+	}
+
+	id, n, err := ReadVarInt(pr.r)
+	if err != nil {
+		return Packet{}, buf, err
+	}
+
+	l := int(length) - n
+	if l < 0 {
+		return Packet{}, buf, errors.WithStack(errValueTooLarge)
+	}
+	if cap(buf) < l {
+		buf = make([]byte, l)
+	}
+	buf = buf[:l]
+	if _, err := io.ReadFull(pr.r, buf); err != nil {
+		return Packet{}, buf, err
+	}
+
+	return Packet{ID: id, Data: buf}, buf, nil
+}
+
+// leftover returns any bytes already read off the underlying io.Reader
+// into pr's internal bufio.Reader but not yet consumed as packet data.
+func (pr *PacketReader) leftover() []byte {
+	n := pr.r.Buffered()
+	if n == 0 {
+		return nil
+	}
+	b, _ := pr.r.Peek(n) // never fails: n == Buffered() needs no further Read
+	out := make([]byte, n)
+	copy(out, b)
+	return out
+}
+
+// PacketWriter writes length-prefixed packets to an underlying io.Writer.
+// It is not safe for concurrent use.
+type PacketWriter struct {
+	w *bufio.Writer
+}
+
+// NewPacketWriter returns a PacketWriter that writes packets to w.
+func NewPacketWriter(w io.Writer) *PacketWriter {
+	return &PacketWriter{w: bufio.NewWriter(w)}
+}
+
+// WritePacket writes p to the stream and flushes the underlying writer.
+func (pw *PacketWriter) WritePacket(p Packet) error {
+	length := LenVarInt(p.ID) + len(p.Data)
+	if length > math.MaxInt32 {
+		return errors.WithStack(errValueTooLarge)
+	}
+
+	if _, err := WriteVarInt(pw.w, int32(length)); err != nil {
+		return err
+	}
+	if _, err := WriteVarInt(pw.w, p.ID); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(p.Data); err != nil {
+		return err
+	}
+
+	return pw.w.Flush()
+}
+
+// writeFramed writes data prefixed with its own length as a VarInt, with
+// no packet id of its own. It is used by writers that build up a frame
+// with its own internal framing, such as CompressedPacketWriter.
+func (pw *PacketWriter) writeFramed(data []byte) error {
+	if length := len(data); length > math.MaxInt32 {
+		return errors.WithStack(errValueTooLarge)
+	}
+
+	if _, err := WriteVarInt(pw.w, int32(len(data))); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(data); err != nil {
+		return err
+	}
+
+	return pw.w.Flush()
+}
@@ -0,0 +1,84 @@
+package mcproto
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestReadVarInt(t *testing.T) {
+	for _, c := range varIntCases {
+		v, n, err := ReadVarInt(bytes.NewReader(c.bytes))
+		if c.err == errBufTooSmall {
+			// a short read surfaces io.EOF/io.ErrUnexpectedEOF rather than errBufTooSmall
+			continue
+		}
+		if v != c.value || n != c.length || errors.Cause(err) != c.err {
+			t.Errorf("have: %#v, want: (%#v, %#v, %#v), got: (%#v, %#v, %#v)",
+				c.bytes,
+				c.value, c.length, c.err,
+				v, n, err)
+		}
+	}
+}
+
+func TestWriteVarInt(t *testing.T) {
+	for _, c := range varIntCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		var buf bytes.Buffer
+		n, err := WriteVarInt(&buf, c.value)
+		if n != c.length || err != nil || bytes.Compare(buf.Bytes(), c.bytes) != 0 {
+			t.Errorf("have: %#v, want: (%#v, %#v), got: (%#v, %#v, %#v)",
+				c.value,
+				c.bytes, c.length,
+				buf.Bytes(), n, err)
+		}
+	}
+}
+
+func TestPacketReaderWriter(t *testing.T) {
+	for _, c := range packetCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+
+		var buf bytes.Buffer
+		if err := NewPacketWriter(&buf).WritePacket(c.value); err != nil {
+			t.Errorf("WritePacket(%#v) failed: %#v", c.value, err)
+			continue
+		}
+		if bytes.Compare(buf.Bytes(), c.bytes) != 0 {
+			t.Errorf("have: %#v, want: %#v, got: %#v", c.value, c.bytes, buf.Bytes())
+		}
+
+		p, err := NewPacketReader(&buf).ReadPacket()
+		if !reflect.DeepEqual(p, c.value) || err != nil {
+			t.Errorf("ReadPacket() have: %#v, want: %#v, got: (%#v, %#v)", c.bytes, c.value, p, err)
+		}
+	}
+}
+
+func TestPacketReaderReadPacketBuf(t *testing.T) {
+	var buf bytes.Buffer
+	for _, c := range packetCases {
+		if c.err != nil {
+			continue // skip invalid cases
+		}
+		buf.Reset()
+		if err := NewPacketWriter(&buf).WritePacket(c.value); err != nil {
+			t.Errorf("WritePacket(%#v) failed: %#v", c.value, err)
+			continue
+		}
+
+		var scratch []byte
+		pr := NewPacketReader(&buf)
+		p, scratch, err := pr.ReadPacketBuf(scratch)
+		if p.ID != c.value.ID || !bytes.Equal(p.Data, c.value.Data) || err != nil {
+			t.Errorf("ReadPacketBuf(%#v) have: %#v, got: (%#v, %#v)", c.bytes, c.value, p, err)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package protocol
+package mcproto
 
 import (
 	"encoding/binary"
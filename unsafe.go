@@ -0,0 +1,31 @@
+package mcproto
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// GetStringUnsafe reads a string the same way GetString does, except the
+// returned string aliases buf instead of being copied out of it. This
+// avoids an allocation on a hot decode path, at the cost of the usual
+// unsafe.String caveat: buf must not be modified or reused for as long
+// as the returned string is alive, since Go strings are assumed
+// immutable everywhere else.
+func GetStringUnsafe(buf []byte) (s string, n int, err error) {
+	length, m, err := GetVarInt(buf)
+	if n += m; err != nil {
+		return "", n, err
+	}
+	if length > math.MaxInt16 {
+		return "", n, errors.WithStack(errValueTooLarge)
+	}
+	if len(buf) < n+int(length) {
+		return "", n, errors.WithStack(errBufTooSmall)
+	}
+
+	s = unsafe.String(unsafe.SliceData(buf[n:n+int(length)]), int(length))
+	n += int(length)
+	return s, n, nil
+}
@@ -0,0 +1,16 @@
+package mcproto
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestGetStringUnsafe(t *testing.T) {
+	for _, c := range stringCases {
+		s, n, err := GetStringUnsafe(c.bytes)
+		if s != c.value || n != c.length || errors.Cause(err) != c.err {
+			t.Errorf("have: %#v, want: (%#v, %#v, %#v), got: (%#v, %#v, %#v)", c.bytes, c.value, c.length, c.err, s, n, err)
+		}
+	}
+}